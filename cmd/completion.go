@@ -0,0 +1,145 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/config"
+	"io"
+	"os"
+	"strings"
+)
+
+var shells = []string{"bash", "zsh", "fish"}
+
+// printCompletion writes the completion script of a shell, generated from
+// the commands and their flags
+func printCompletion(w io.Writer, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "completion requires a shell: %s\n", strings.Join(shells, ", "))
+		return exitError
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(w)
+	case "zsh":
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(w)
+	case "fish":
+		writeFishCompletion(w)
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported shell %q, expected one of %s\n", args[0], strings.Join(shells, ", "))
+		return exitError
+	}
+	return exitClean
+}
+
+// topLevelWords returns the first word of every command, once
+func topLevelWords() []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, command := range config.Commands {
+		word := strings.Fields(command.Name)[0]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func flagNames(command config.Command) []string {
+	var names []string
+	command.VisitFlags(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+func writeBashCompletion(w io.Writer) {
+	scan, _ := config.Lookup(config.CommandScan)
+
+	fmt.Fprintf(w, "_secret_scan() {\n")
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" words=\" ${COMP_WORDS[*]:1:COMP_CWORD-1} \" flags\n")
+	fmt.Fprintf(w, "    if [ \"$COMP_CWORD\" -eq 1 ] && [[ $cur != -* ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(topLevelWords(), " "))
+	fmt.Fprintf(w, "        return\n    fi\n")
+
+	// subcommands of groups such as patterns
+	fmt.Fprintf(w, "    if [ \"$COMP_CWORD\" -eq 2 ]; then\n        case \"${COMP_WORDS[1]}\" in\n")
+	for _, word := range topLevelWords() {
+		if subcommands := config.Subcommands(word); len(subcommands) > 0 {
+			fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", word, strings.Join(subcommands, " "))
+		}
+	}
+	fmt.Fprintf(w, "        completion) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", strings.Join(shells, " "))
+	fmt.Fprintf(w, "        esac\n    fi\n")
+
+	fmt.Fprintf(w, "    [[ $cur == -* ]] || return\n")
+	fmt.Fprintf(w, "    case \"$words\" in\n")
+	for _, command := range config.Commands {
+		if len(command.Flags) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "    \" %s \"*) flags=%q ;;\n", command.Name, strings.Join(flagNames(command), " "))
+	}
+	fmt.Fprintf(w, "    *) flags=%q ;;\n", strings.Join(flagNames(scan), " "))
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "    COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o default -F _secret_scan %s\n", config.ProgramName)
+}
+
+func writeFishCompletion(w io.Writer) {
+	program := config.ProgramName
+
+	for _, word := range topLevelWords() {
+		summary := ""
+		if command, ok := config.Lookup(word); ok {
+			summary = command.Summary
+		}
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -f -a %s -d %s\n", program, word, fishQuote(summary))
+
+		for _, name := range config.Subcommands(word) {
+			command, _ := config.Lookup(word + " " + name)
+			condition := fmt.Sprintf("__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s",
+				word, strings.Join(config.Subcommands(word), " "))
+			fmt.Fprintf(w, "complete -c %s -n %s -f -a %s -d %s\n", program, fishQuote(condition), name, fishQuote(command.Summary))
+		}
+	}
+	fmt.Fprintf(w, "complete -c %s -n %s -f -a %q\n", program, fishQuote("__fish_seen_subcommand_from completion"), strings.Join(shells, " "))
+
+	for _, command := range config.Commands {
+		words := strings.Fields(command.Name)
+		condition := "__fish_seen_subcommand_from " + words[0]
+		if len(words) == 2 {
+			condition += "; and __fish_seen_subcommand_from " + words[1]
+		}
+		if command.Name == config.CommandScan {
+			condition = "__fish_use_subcommand; or " + condition
+		}
+
+		command.VisitFlags(func(f *flag.Flag) {
+			fmt.Fprintf(w, "complete -c %s -n %s -o %s -d %s\n", program, fishQuote(condition), f.Name, fishQuote(f.Usage))
+		})
+	}
+}
+
+// fishQuote quotes a fish argument
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
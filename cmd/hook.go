@@ -0,0 +1,40 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/clarityoverclever/secret-scan/config"
+	"github.com/clarityoverclever/secret-scan/internal/githook"
+	"log/slog"
+	"os"
+)
+
+// installHook writes a pre-commit hook running the staged scan
+func installHook(cfg config.Config, log *slog.Logger) int {
+	executable, err := os.Executable()
+	if err != nil {
+		log.Error("failed to locate secret-scan executable", "error", err)
+		return exitError
+	}
+
+	path, err := githook.Install(cfg.ScanPath, executable, cfg.Force)
+	if err != nil {
+		log.Error("failed to install hook", "error", err)
+		return exitError
+	}
+
+	log.Info("pre-commit hook installed", "path", path)
+	return exitClean
+}
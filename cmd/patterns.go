@@ -0,0 +1,267 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/config"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/plugins"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"github.com/clarityoverclever/secret-scan/internal/scan"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// listPatterns writes the loaded patterns as a table or JSON
+func listPatterns(cfg config.Config, log *slog.Logger) int {
+	loader, patterns, err := loadPatterns(cfg, log, redact.None)
+	if err != nil {
+		log.Error("failed to load patterns", "error", err)
+		return exitError
+	}
+	defer loader.Close()
+
+	if cfg.JSON {
+		type entry struct {
+			Name         string          `json:"name"`
+			Severity     models.Severity `json:"severity"`
+			Regex        string          `json:"regex"`
+			SecretGroup  int             `json:"secret_group,omitempty"`
+			Multiline    bool            `json:"multiline,omitempty"`
+			ContextLines int             `json:"context_lines,omitempty"`
+			Validators   string          `json:"validators,omitempty"`
+		}
+
+		entries := make([]entry, 0, len(patterns))
+		for _, pattern := range patterns {
+			entries = append(entries, entry{
+				Name:         pattern.Name,
+				Severity:     pattern.Severity,
+				Regex:        pattern.Regex.String(),
+				SecretGroup:  pattern.SecretGroup,
+				Multiline:    pattern.Multiline,
+				ContextLines: pattern.ContextLines,
+				Validators:   describeValidators(pattern),
+			})
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			log.Error("failed to write patterns", "error", err)
+			return exitError
+		}
+		return exitClean
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tSEVERITY\tVALIDATORS\tOPTIONS")
+	for _, pattern := range patterns {
+		var options []string
+		if pattern.Multiline {
+			options = append(options, "multiline")
+		}
+		if pattern.SecretGroup > 0 {
+			options = append(options, fmt.Sprintf("secret group %d", pattern.SecretGroup))
+		}
+		if pattern.ContextLines > 0 {
+			options = append(options, fmt.Sprintf("%d context lines", pattern.ContextLines))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", pattern.Name, pattern.Severity, orDash(describeValidators(pattern)), orDash(strings.Join(options, ", ")))
+	}
+	if err := table.Flush(); err != nil {
+		log.Error("failed to write patterns", "error", err)
+		return exitError
+	}
+	return exitClean
+}
+
+// describeValidators formats the validators of a pattern, e.g.
+// "entropy{min=4.2} and context{keywords=[stripe]}"
+func describeValidators(pattern models.CompiledPattern) string {
+	described := make([]string, 0, len(pattern.Validators))
+	for _, spec := range pattern.Validators {
+		if len(spec.Params) == 0 {
+			described = append(described, spec.Name)
+			continue
+		}
+
+		keys := make([]string, 0, len(spec.Params))
+		for key := range spec.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		params := make([]string, 0, len(keys))
+		for _, key := range keys {
+			params = append(params, fmt.Sprintf("%s=%v", key, spec.Params[key]))
+		}
+		described = append(described, spec.Name+"{"+strings.Join(params, ", ")+"}")
+	}
+
+	if pattern.ValidatorAny {
+		return strings.Join(described, " or ")
+	}
+	return strings.Join(described, " and ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// testPatterns shows every match of the loaded patterns in the input files,
+// or stdin, and whether the validators of the pattern accept it. The input is
+// read by the scanner, so it is matched exactly as a scan would.
+func testPatterns(cfg config.Config, log *slog.Logger) int {
+	redactMode, err := redact.ParseMode(cfg.Redact)
+	if err != nil {
+		log.Error("invalid -redact mode", "error", err)
+		return exitError
+	}
+
+	loader, patterns, err := loadPatterns(cfg, log, redactMode)
+	if err != nil {
+		log.Error("failed to load patterns", "error", err)
+		return exitError
+	}
+	defer loader.Close()
+
+	var pluginExtractors []extractors.Extractor
+	if cfg.PluginsPath != "" {
+		extractorLoader := plugins.NewExtractorLoader(log)
+		defer extractorLoader.Close()
+
+		pluginExtractors, err = extractorLoader.LoadExtractors(cfg.PluginsPath)
+		if err != nil {
+			log.Error("failed to load extractor plugins", "error", err)
+			return exitError
+		}
+	}
+
+	validated := make(map[string]bool, len(patterns))
+	for _, pattern := range patterns {
+		validated[pattern.Name] = pattern.Validator != nil
+	}
+
+	inputs := cfg.Args
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	for _, input := range inputs {
+		matches := &recorder{}
+		scanner := scan.NewScanner(patterns, matches, log, scan.Options{
+			Workers:        1,
+			ShowSuppressed: true,
+			KeepRejected:   true,
+			Extractors:     pluginExtractors,
+			Redact:         redactMode,
+			ArchiveLimits:  extractors.DefaultArchiveLimits,
+		})
+
+		if err := scanInput(scanner, input); err != nil {
+			log.Error("failed to read input", "path", input, "error", err)
+			return exitError
+		}
+
+		sort.SliceStable(matches.findings, func(i, j int) bool {
+			a, b := matches.findings[i], matches.findings[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+
+		for _, finding := range matches.findings {
+			verdict := "no validators"
+			if finding.Rejected {
+				verdict = "rejected by validators"
+			} else if validated[finding.Pattern] {
+				verdict = "accepted by validators"
+			}
+			if finding.Suppressed {
+				verdict += ", suppressed inline"
+			}
+			fmt.Fprintf(os.Stdout, "%s:%d:%d: %s (%s) %s [%s]\n",
+				finding.File, finding.Line, finding.Column, finding.Pattern, finding.Severity, finding.Match, verdict)
+		}
+	}
+
+	return exitClean
+}
+
+// scanInput scans a file, or stdin for "-"
+func scanInput(scanner *scan.Scanner, input string) error {
+	if input == "-" {
+		return scanner.ScanReader(context.Background(), input, os.Stdin)
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return scanner.ScanReader(context.Background(), input, file)
+}
+
+// recorder keeps the findings written by a scanner
+type recorder struct {
+	findings []models.Finding
+}
+
+func (r *recorder) Write(finding models.Finding) error {
+	r.findings = append(r.findings, finding)
+	return nil
+}
+
+func (r *recorder) Close() error {
+	return nil
+}
+
+// lintPatterns reports problems in the pattern files
+func lintPatterns(cfg config.Config, log *slog.Logger) int {
+	loader, definitions, filter, err := loadDefinitions(cfg, log)
+	if err != nil {
+		log.Error("failed to load patterns", "error", err)
+		return exitError
+	}
+	defer loader.Close()
+
+	issues := loader.Lint(definitions, filter)
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stdout, "%s: %s\n", issue.Pattern, issue.Problem)
+	}
+
+	if len(issues) > 0 {
+		log.Info("pattern problems found", "count", len(issues))
+		return exitFindings
+	}
+
+	log.Info("no pattern problems found", "patterns", len(definitions))
+	return exitClean
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/clarityoverclever/secret-scan/config"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/output"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// report converts JSON findings written by a scan to -format
+func report(cfg config.Config, log *slog.Logger) int {
+	failOn, err := models.ParseSeverity(cfg.FailOn)
+	if err != nil {
+		log.Error("invalid -fail-on severity", "error", err)
+		return exitError
+	}
+
+	redactMode, err := redact.ParseMode(cfg.Redact)
+	if err != nil {
+		log.Error("invalid -redact mode", "error", err)
+		return exitError
+	}
+
+	outputFile, err := createOutput(cfg, log)
+	if err != nil {
+		log.Error("failed to create output file", "error", err)
+		return exitError
+	}
+	if outputFile != os.Stdout {
+		defer outputFile.Close()
+	}
+
+	writer, err := output.New(cfg.Format, outputFile, nil)
+	if err != nil {
+		log.Error("failed to create output writer", "error", err)
+		return exitError
+	}
+
+	inputs := cfg.Args
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	var count int
+	maxSeverity := models.SeverityUnknown
+	for _, input := range inputs {
+		err := readFindings(input, func(finding models.Finding) error {
+			if finding.MatchSHA256 == "" {
+				finding.MatchSHA256 = redact.Hash(finding.Match)
+			}
+			finding.Context = redactMode.Text(finding.Context, finding.Match)
+			finding.Match = redactMode.Secret(finding.Match)
+
+			if !finding.Suppressed && finding.Severity > maxSeverity {
+				maxSeverity = finding.Severity
+			}
+			count++
+			return writer.Write(finding)
+		})
+		if err != nil {
+			log.Error("failed to convert findings", "path", input, "error", err)
+			return exitError
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Error("failed to write output", "error", err)
+		return exitError
+	}
+
+	log.Info("report written", "findings", count)
+
+	if maxSeverity >= failOn {
+		log.Info("findings at or above threshold", "fail_on", failOn, "max_severity", maxSeverity)
+		return exitFindings
+	}
+	return exitClean
+}
+
+// readFindings decodes the JSON findings of a file, or stdin for "-"
+func readFindings(path string, fn func(models.Finding) error) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	decoder := json.NewDecoder(r)
+	for {
+		var finding models.Finding
+		if err := decoder.Decode(&finding); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(finding); err != nil {
+			return err
+		}
+	}
+}
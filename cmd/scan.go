@@ -0,0 +1,303 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"github.com/clarityoverclever/secret-scan/config"
+	"github.com/clarityoverclever/secret-scan/internal/baseline"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/output"
+	"github.com/clarityoverclever/secret-scan/internal/plugins"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"github.com/clarityoverclever/secret-scan/internal/scan"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// runScan runs the scan, git, staged and baseline create commands
+func runScan(cfg config.Config, log *slog.Logger) int {
+	failOn, err := models.ParseSeverity(cfg.FailOn)
+	if err != nil {
+		log.Error("invalid -fail-on severity", "error", err)
+		return exitError
+	}
+
+	redactMode, err := redact.ParseMode(cfg.Redact)
+	if err != nil {
+		log.Error("invalid -redact mode", "error", err)
+		return exitError
+	}
+
+	// create output file if specified
+	outputFile, err := createOutput(cfg, log)
+	if err != nil {
+		log.Error("failed to create output file", "error", err)
+		return exitError
+	}
+	if outputFile != os.Stdout {
+		defer outputFile.Close()
+	}
+
+	loader, compiledPatterns, err := loadPatterns(cfg, log, redactMode)
+	if err != nil {
+		log.Error("failed to load patterns", "error", err)
+		return exitError
+	}
+	defer loader.Close()
+
+	// load extractor plugins, each plugin file runs in its own VM
+	var pluginExtractors []extractors.Extractor
+	if cfg.PluginsPath != "" {
+		log.Debug("loading extractor plugins", "path", cfg.PluginsPath)
+		extractorLoader := plugins.NewExtractorLoader(log)
+		defer extractorLoader.Close()
+
+		pluginExtractors, err = extractorLoader.LoadExtractors(cfg.PluginsPath)
+		if err != nil {
+			log.Error("failed to load extractor plugins", "error", err)
+			return exitError
+		}
+	}
+
+	// init output writer
+	writer, err := output.New(cfg.Format, outputFile, compiledPatterns)
+	if err != nil {
+		log.Error("failed to create output writer", "error", err)
+		return exitError
+	}
+
+	allowlist, err := compileAllowlist(cfg.Allowlist)
+	if err != nil {
+		log.Error("invalid allowlist", "error", err)
+		return exitError
+	}
+
+	// load baseline of accepted findings
+	var accepted *baseline.Baseline
+	if cfg.BaselinePath != "" {
+		log.Debug("loading baseline", "path", cfg.BaselinePath)
+		accepted, err = baseline.Load(cfg.BaselinePath)
+		if err != nil {
+			log.Error("failed to load baseline", "error", err)
+			return exitError
+		}
+		log.Debug("baseline loaded", "fingerprints", accepted.Len())
+	}
+
+	// init scanner
+	scanner := scan.NewScanner(compiledPatterns, writer, log, scan.Options{
+		Workers:        cfg.Threads,
+		Excludes:       append(cfg.Excludes, selfExcludes(cfg)...),
+		Includes:       cfg.Includes,
+		ShowSuppressed: cfg.ShowSuppressed,
+		Baseline:       accepted,
+		Allowlist:      allowlist,
+		Extractors:     pluginExtractors,
+		Redact:         redactMode,
+		ArchiveLimits: extractors.ArchiveLimits{
+			MaxDepth:     cfg.ArchiveDepth,
+			MaxTotalSize: cfg.ArchiveMaxSize * 1024 * 1024,
+			MaxRatio:     cfg.ArchiveMaxRatio,
+		},
+	})
+
+	// add background context for the scanner
+	ctx := context.Background()
+
+	log.Debug("scanning path", "path", cfg.ScanPath)
+
+	log.Info("starting scan")
+
+	switch cfg.Command {
+	case config.CommandGit:
+		err = scanner.ScanGitHistory(ctx, cfg.ScanPath, scan.GitOptions{
+			Range: cfg.GitRange,
+			Since: cfg.GitSince,
+		})
+	case config.CommandStaged:
+		err = scanner.ScanStaged(ctx, cfg.ScanPath)
+	default:
+		err = scanner.ScanPath(ctx, cfg.ScanPath)
+	}
+
+	if err != nil {
+		log.Error("scan failed", "error", err)
+		return exitError
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Error("failed to write output", "error", err)
+		return exitError
+	}
+
+	stats := scanner.Stats()
+	log.Info("scan finished",
+		"findings", stats.Findings, "suppressed", stats.Suppressed, "baselined", stats.Baselined, "allowlisted", stats.Allowed)
+
+	if cfg.Command == config.CommandBaselineCreate {
+		log.Info("baseline created", "path", cfg.OutputFilename, "findings", stats.Findings)
+		return exitClean
+	}
+
+	if stats.MaxSeverity >= failOn {
+		log.Info("findings at or above threshold", "fail_on", failOn, "max_severity", stats.MaxSeverity)
+		return exitFindings
+	}
+	return exitClean
+}
+
+// createOutput creates the -out file, stdout when none is set
+func createOutput(cfg config.Config, log *slog.Logger) (*os.File, error) {
+	if cfg.OutputFilename == "" {
+		return os.Stdout, nil
+	}
+
+	log.Debug("creating output file", "path", cfg.OutputFilename)
+	return os.Create(cfg.OutputFilename)
+}
+
+// loadPatterns loads the pattern files and compiles the patterns selected by
+// the configuration. The loader runs Lua validators and must be closed once
+// the patterns are no longer used.
+func loadPatterns(cfg config.Config, log *slog.Logger, redactMode redact.Mode) (*plugins.PatternLoader, []models.CompiledPattern, error) {
+	loader, definitions, filter, err := loadDefinitions(cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// validators defined by pattern files run on the loader VM
+	compiledPatterns, err := loader.CompilePatterns(definitions, filter, plugins.CompileOptions{
+		Lenient: cfg.LenientValidators,
+		Redact:  redactMode,
+	})
+	if err != nil {
+		loader.Close()
+		return nil, nil, err
+	}
+
+	return loader, compiledPatterns, nil
+}
+
+// loadDefinitions loads the pattern files without compiling them
+func loadDefinitions(cfg config.Config, log *slog.Logger) (*plugins.PatternLoader, []models.PatternDefinition, plugins.PatternFilter, error) {
+	filter, err := patternFilter(cfg)
+	if err != nil {
+		return nil, nil, filter, err
+	}
+
+	// start plugin VM
+	log.Debug("initializing lua plugin VM")
+	loader := plugins.NewPatternLoader(log)
+
+	// import patterns
+	log.Debug("importing patterns")
+	definitions, err := loader.LoadPatterns(cfg.PatternsPath, cfg.NoDefaultPatterns)
+	if err != nil {
+		loader.Close()
+		return nil, nil, filter, err
+	}
+
+	overrideValidators(definitions, cfg.Validators, log)
+	return loader, definitions, filter, nil
+}
+
+// overrideValidators replaces the validators of the patterns configured in
+// the validators setting, keeping their validator mode
+func overrideValidators(definitions []models.PatternDefinition, overrides map[string]config.ValidatorOverride, log *slog.Logger) {
+	for name, override := range overrides {
+		found := false
+		for index := range definitions {
+			if !strings.EqualFold(definitions[index].Name, name) {
+				continue
+			}
+			found = true
+
+			specs := make([]models.ValidatorSpec, 0, len(override.Validators))
+			for _, spec := range override.Validators {
+				specs = append(specs, models.ValidatorSpec{Name: spec.Name, Params: spec.Params})
+			}
+			definitions[index].Validators = specs
+			log.Debug("pattern validators configured", "pattern", definitions[index].Name, "origin", override.Origin)
+		}
+
+		if !found {
+			log.Warn("validators configured for unknown pattern", "pattern", name, "origin", override.Origin)
+		}
+	}
+}
+
+// selfExcludes keeps the scanner from reporting the secrets recorded in its
+// own output, baseline and log files when they live inside the scanned tree
+func selfExcludes(cfg config.Config) []string {
+	var excludes []string
+
+	for _, path := range []string{cfg.OutputFilename, cfg.BaselinePath, cfg.LogFile} {
+		if path == "" {
+			continue
+		}
+
+		root, _ := filepath.Abs(cfg.ScanPath)
+		path, _ = filepath.Abs(path)
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		excludes = append(excludes, "/"+filepath.ToSlash(rel))
+	}
+
+	return excludes
+}
+
+// patternFilter converts the pattern selection flags into a loader filter
+func patternFilter(cfg config.Config) (plugins.PatternFilter, error) {
+	filter := plugins.PatternFilter{
+		Only: cfg.OnlyPatterns,
+		Skip: cfg.SkipPatterns,
+	}
+
+	var err error
+	if filter.MinSeverity, err = models.ParseSeverity(cfg.MinSeverity); err != nil {
+		return filter, err
+	}
+
+	for _, name := range cfg.Severities {
+		severity, err := models.ParseSeverity(name)
+		if err != nil {
+			return filter, err
+		}
+		filter.Severities = append(filter.Severities, severity)
+	}
+
+	return filter, nil
+}
+
+// compileAllowlist compiles the expressions of secrets to ignore
+func compileAllowlist(expressions []string) ([]*regexp.Regexp, error) {
+	allowlist := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		allowlist = append(allowlist, regex)
+	}
+	return allowlist, nil
+}
@@ -15,74 +15,128 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/config"
+	"github.com/clarityoverclever/secret-scan/internal/logger"
+	"io"
+	"log/slog"
 	"os"
-	"secret-scan/config"
-	"secret-scan/internal/logger"
-	"secret-scan/internal/plugins"
-	"secret-scan/internal/scan"
+	"strings"
 )
 
-func main() {
-	var err error
-
-	// parse flags
-	cfg := config.ParseFlags()
+// exit codes
+const (
+	exitClean    = 0 // no findings at or above the -fail-on threshold
+	exitFindings = 1 // findings at or above the -fail-on threshold
+	exitError    = 2 // the scan could not be completed
+)
 
-	// init logger
-	log := logger.SetupLogger(cfg.Silent, cfg.Verbose)
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	// create output file if specified
-	var outputFile *os.File
-	if cfg.OutputFilename != "" {
-		log.Debug("creating output file", "path", cfg.OutputFilename)
-		outputFile, err = os.Create(cfg.OutputFilename)
-		if err != nil {
-			log.Error("failed to create output file", "error", err)
-			os.Exit(1)
+func run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			return help(os.Stdout, args[1:])
 		}
-		defer outputFile.Close()
-	} else {
-		outputFile = os.Stdout
 	}
 
-	// init json encoder
-	encoder := json.NewEncoder(outputFile)
+	// parse flags over the configuration files and environment
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid configuration:", err)
+		return exitError
+	}
 
-	// start plugin VM
-	log.Debug("initializing lua plugin VM")
-	loader := plugins.NewPatternLoader(log)
-	defer loader.Close()
+	// commands writing to stdout without diagnostics
+	switch cfg.Command {
+	case config.CommandVersion:
+		return printVersion(os.Stdout)
+	case config.CommandCompletion:
+		return printCompletion(os.Stdout, cfg.Args)
+	case config.CommandConfigPrint:
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to print configuration:", err)
+			return exitError
+		}
+		return exitClean
+	}
 
-	// import patterns
-	log.Debug("importing patterns")
-	importedPatterns, err := loader.LoadPatterns(cfg.PatternsPath, cfg.NoDefaultPatterns)
+	// init logger, diagnostics are kept off stdout which carries the findings
+	log, closeLog, err := setupLogger(cfg)
 	if err != nil {
-		log.Error("failed to load patterns", "error", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	defer closeLog()
+
+	switch cfg.Command {
+	case config.CommandHookInstall:
+		return installHook(cfg, log)
+	case config.CommandReport:
+		return report(cfg, log)
+	case config.CommandPatternsList:
+		return listPatterns(cfg, log)
+	case config.CommandPatternsTest:
+		return testPatterns(cfg, log)
+	case config.CommandPatternsLint:
+		return lintPatterns(cfg, log)
+	default:
+		return runScan(cfg, log)
 	}
+}
 
-	compiledPatterns, err := loader.CompilePatterns(importedPatterns)
-	if err != nil {
-		log.Error("failed to compile patterns", "error", err)
-		os.Exit(1)
+// setupLogger creates the logger of the configuration, the returned
+// function closes the -log-file
+func setupLogger(cfg config.Config) (*slog.Logger, func(), error) {
+	logOptions := logger.Options{
+		Silent:  cfg.Silent,
+		Verbose: cfg.Verbose,
+		Format:  cfg.LogFormat,
 	}
 
-	// init scanner
-	scanner := scan.NewScanner(compiledPatterns, encoder, log, cfg.Threads)
+	closeLog := func() {}
+	if cfg.LogFile != "" {
+		logFile, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		closeLog = func() { logFile.Close() }
+		logOptions.File = logFile
+	}
 
-	// add background context for the scanner
-	ctx := context.Background()
+	log, err := logger.SetupLogger(logOptions)
+	if err != nil {
+		closeLog()
+		return nil, nil, fmt.Errorf("invalid -log-format: %w", err)
+	}
+	return log, closeLog, nil
+}
 
-	log.Debug("scanning path", "path", cfg.ScanPath)
+// help writes the usage of a command, or lists the commands
+func help(w io.Writer, args []string) int {
+	if len(args) == 0 {
+		config.PrintUsage(w)
+		return exitClean
+	}
 
-	log.Info("starting scan")
+	if command, ok := config.Lookup(strings.Join(args, " ")); ok {
+		command.PrintHelp(w)
+		return exitClean
+	}
 
-	if err := scanner.ScanPath(ctx, cfg.ScanPath); err != nil {
-		log.Error("scan failed", "error", err)
-		os.Exit(1)
+	// a group such as "patterns" lists its subcommands
+	if subcommands := config.Subcommands(args[0]); len(args) == 1 && len(subcommands) > 0 {
+		fmt.Fprintf(w, "Usage: %s %s <command> [flags] [arguments]\n\nCommands:\n", config.ProgramName, args[0])
+		for _, name := range subcommands {
+			command, _ := config.Lookup(args[0] + " " + name)
+			fmt.Fprintf(w, "  %-16s %s\n", command.Name, command.Summary)
+		}
+		return exitClean
 	}
 
-	log.Info("scan finished")
+	fmt.Fprintf(os.Stderr, "unknown command %q, run '%s help' for a list of commands\n", strings.Join(args, " "), config.ProgramName)
+	return exitError
 }
@@ -0,0 +1,50 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// printVersion writes the version, falling back to the module version and
+// revision recorded by the go toolchain
+func printVersion(w io.Writer) int {
+	current := version
+	revision := ""
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if current == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			current = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+				revision = setting.Value[:12]
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "secret-scan %s", current)
+	if revision != "" {
+		fmt.Fprintf(w, " (%s)", revision)
+	}
+	fmt.Fprintf(w, " %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return exitClean
+}
@@ -0,0 +1,354 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Commands, the first word of a command may group several subcommands
+const (
+	CommandScan           = "scan"
+	CommandGit            = "git"
+	CommandStaged         = "staged"
+	CommandBaselineCreate = "baseline create"
+	CommandReport         = "report"
+	CommandPatternsList   = "patterns list"
+	CommandPatternsTest   = "patterns test"
+	CommandPatternsLint   = "patterns lint"
+	CommandHookInstall    = "hook install"
+	CommandConfigPrint    = "config print"
+	CommandVersion        = "version"
+	CommandCompletion     = "completion"
+)
+
+// ProgramName is used in usage and help text
+const ProgramName = "secret-scan"
+
+// Command describes a subcommand and the flags it accepts
+type Command struct {
+	Name        string
+	Usage       string // the arguments following the flags
+	Summary     string
+	Description string
+	Flags       []string
+}
+
+// flags shared by groups of commands
+var (
+	commonFlags  = []string{"config", "silent", "verbose", "log-file", "log-format"}
+	patternFlags = []string{"patterns", "no-default-patterns", "min-severity", "severity", "only-patterns", "skip-patterns", "lenient-validators"}
+	scanFlags    = []string{"plugins", "threads", "exclude", "include", "allowlist", "show-suppressed", "baseline", "archive-depth", "archive-max-size", "archive-max-ratio"}
+	outputFlags  = []string{"out", "format", "redact", "fail-on"}
+)
+
+func flagList(groups ...[]string) []string {
+	return slices.Concat(groups...)
+}
+
+var Commands = []Command{
+	{
+		Name:    CommandScan,
+		Usage:   "[path]",
+		Summary: "scan files and archives for secrets",
+		Description: "Scans the files below path, the current directory by default. Findings are written to\n" +
+			"stdout or -out, diagnostics to stderr. The command may be omitted: secret-scan [flags] [path].",
+		Flags: flagList(commonFlags, patternFlags, scanFlags, outputFlags),
+	},
+	{
+		Name:        CommandGit,
+		Usage:       "[repository]",
+		Summary:     "scan the lines added by each commit of a git repository",
+		Description: "Scans the history of the repository, all refs unless -range is set.",
+		Flags:       flagList(commonFlags, patternFlags, scanFlags, outputFlags, []string{"range", "since"}),
+	},
+	{
+		Name:        CommandStaged,
+		Usage:       "[repository]",
+		Summary:     "scan the changes staged for commit",
+		Description: "Scans the lines added by the staged changes, as run by the pre-commit hook.",
+		Flags:       flagList(commonFlags, patternFlags, scanFlags, outputFlags),
+	},
+	{
+		Name:    CommandBaselineCreate,
+		Usage:   "[path]",
+		Summary: "record the current findings as accepted",
+		Description: "Writes every current finding to -out, " + DefaultBaselineFile + " by default. Scans\n" +
+			"given the file with -baseline only report findings missing from it.",
+		Flags: flagList(commonFlags, patternFlags, scanFlags, []string{"out", "redact"}),
+	},
+	{
+		Name:    CommandReport,
+		Usage:   "[findings.json ...]",
+		Summary: "convert findings written by a scan",
+		Description: "Reads JSON findings from the files, or stdin, and writes them in -format with -redact\n" +
+			"applied. Exits 1 when findings at or above -fail-on are reported.",
+		Flags: flagList(commonFlags, outputFlags),
+	},
+	{
+		Name:        CommandPatternsList,
+		Summary:     "list the loaded patterns",
+		Description: "Lists the patterns selected by the pattern flags, as a table or with -json as JSON.",
+		Flags:       flagList(commonFlags, patternFlags, []string{"json"}),
+	},
+	{
+		Name:    CommandPatternsTest,
+		Usage:   "[file ...]",
+		Summary: "show the pattern matches in sample input",
+		Description: "Matches the loaded patterns against the files, or stdin, and shows every match with\n" +
+			"whether its validators accept it. Files are read as a scan reads them, including archives and\n" +
+			"-plugins formats. Inline suppressions are shown rather than applied, baselines and allowlists\n" +
+			"do not apply.",
+		Flags: flagList(commonFlags, patternFlags, []string{"plugins", "redact"}),
+	},
+	{
+		Name:    CommandPatternsLint,
+		Summary: "check pattern files for mistakes",
+		Description: "Checks the loaded patterns for invalid regexes, secret groups and validators, duplicate\n" +
+			"names and regexes matching empty text. Exits 1 when problems are found.",
+		Flags: flagList(commonFlags, patternFlags),
+	},
+	{
+		Name:        CommandHookInstall,
+		Usage:       "[repository]",
+		Summary:     "install a pre-commit hook running the staged scan",
+		Description: "Writes a pre-commit hook running secret-scan staged. An existing hook is kept unless -force is set.",
+		Flags:       flagList(commonFlags, []string{"force"}),
+	},
+	{
+		Name:    CommandConfigPrint,
+		Usage:   "[path]",
+		Summary: "show the effective configuration",
+		Description: "Prints the configuration which applies to a scan of path as YAML, each setting commented\n" +
+			"with the default, file, environment variable or flag it came from.",
+		Flags: flagList(commonFlags, patternFlags, scanFlags, outputFlags, []string{"range", "since"}),
+	},
+	{
+		Name:    CommandVersion,
+		Summary: "print the version",
+	},
+	{
+		Name:        CommandCompletion,
+		Usage:       "bash|zsh|fish",
+		Summary:     "print a shell completion script",
+		Description: "Prints a completion script for the shell, e.g. source <(secret-scan completion bash).",
+	},
+}
+
+// FindCommand returns the command named by the leading args and the args
+// following its name. Args not starting with a command name are a scan.
+func FindCommand(args []string) (Command, []string, error) {
+	for _, command := range Commands {
+		words := strings.Fields(command.Name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return command, args[len(words):], nil
+		}
+	}
+
+	// a group such as "patterns" without a valid subcommand
+	if len(args) > 0 {
+		if group := Subcommands(args[0]); len(group) > 0 {
+			return Command{}, nil, fmt.Errorf("%s requires a subcommand: %s", args[0], strings.Join(group, ", "))
+		}
+	}
+
+	scan, _ := Lookup(CommandScan)
+	return scan, args, nil
+}
+
+// Lookup returns the command of the given name
+func Lookup(name string) (Command, bool) {
+	for _, command := range Commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// Subcommands returns the second words of the commands grouped under group
+func Subcommands(group string) []string {
+	var names []string
+	for _, command := range Commands {
+		if words := strings.Fields(command.Name); len(words) == 2 && words[0] == group {
+			names = append(names, words[1])
+		}
+	}
+	return names
+}
+
+// flagSet defines the flags of the command bound to cfg
+func (c Command) flagSet(cfg *Config) *flag.FlagSet {
+	flags := flag.NewFlagSet(ProgramName+" "+c.Name, flag.ExitOnError)
+	definitions := flagDefinitions(cfg)
+	for _, name := range c.Flags {
+		definitions[name](flags)
+	}
+
+	flags.Usage = func() {
+		c.PrintUsage(flags.Output())
+		if len(c.Flags) > 0 {
+			fmt.Fprintf(flags.Output(), "\nFlags:\n")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+// VisitFlags calls fn for each flag of the command in lexical order
+func (c Command) VisitFlags(fn func(f *flag.Flag)) {
+	c.flagSet(&Config{}).VisitAll(fn)
+}
+
+// PrintHelp writes the usage, description and flags of the command
+func (c Command) PrintHelp(w io.Writer) {
+	flags := c.flagSet(&Config{})
+	flags.SetOutput(w)
+	flags.Usage()
+}
+
+// PrintUsage writes the usage line and description of the command
+func (c Command) PrintUsage(w io.Writer) {
+	usage := ProgramName + " " + c.Name
+	if len(c.Flags) > 0 {
+		usage += " [flags]"
+	}
+	if c.Usage != "" {
+		usage += " " + c.Usage
+	}
+
+	fmt.Fprintf(w, "Usage: %s\n\n", usage)
+	description := c.Description
+	if description == "" {
+		description = strings.ToUpper(c.Summary[:1]) + c.Summary[1:] + "."
+	}
+	fmt.Fprintln(w, description)
+}
+
+// PrintUsage writes the commands and how to get help on them
+func PrintUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n       %s [flags] [path]\n\nCommands:\n", ProgramName, ProgramName)
+	for _, command := range Commands {
+		fmt.Fprintf(w, "  %-16s %s\n", command.Name, command.Summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' or '%s <command> -h' for the flags of a command.\n", ProgramName, ProgramName)
+}
+
+// allFlags defines every flag bound to cfg, the set configuration files and
+// environment variables apply to
+func allFlags(cfg *Config) *flag.FlagSet {
+	flags := flag.NewFlagSet(ProgramName, flag.ContinueOnError)
+	definitions := flagDefinitions(cfg)
+	for _, name := range flagOrder {
+		definitions[name](flags)
+	}
+	return flags
+}
+
+// flagOrder lists every flag once
+var flagOrder = flagList(commonFlags, patternFlags, scanFlags, outputFlags, []string{"range", "since", "force", "json"})
+
+// flagDefinitions returns a function defining each flag bound to cfg
+func flagDefinitions(cfg *Config) map[string]func(flags *flag.FlagSet) {
+	return map[string]func(flags *flag.FlagSet){
+		"config": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.ConfigPath, "config", "", "configuration file, applied over the user and repository configuration files")
+		},
+		"silent":  func(flags *flag.FlagSet) { flags.BoolVar(&cfg.Silent, "silent", false, "suppress output") },
+		"verbose": func(flags *flag.FlagSet) { flags.BoolVar(&cfg.Verbose, "verbose", false, "enable verbose output") },
+		"log-file": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.LogFile, "log-file", "", "append diagnostics to this file, only warnings and errors are also written to stderr")
+		},
+		"log-format": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.LogFormat, "log-format", "text", "diagnostics format: text or json")
+		},
+		"no-default-patterns": func(flags *flag.FlagSet) {
+			flags.BoolVar(&cfg.NoDefaultPatterns, "no-default-patterns", false, "disable loading of default patterns")
+		},
+		"out": func(flags *flag.FlagSet) { flags.StringVar(&cfg.OutputFilename, "out", "", "output file") },
+		"patterns": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.PatternsPath, "patterns", "", "path to custome patterns file")
+		},
+		"plugins": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.PluginsPath, "plugins", "", "directory of lua extractor plugins")
+		},
+		"threads": func(flags *flag.FlagSet) {
+			flags.IntVar(&cfg.Threads, "threads", runtime.NumCPU()-1, "number of threads")
+		},
+		"show-suppressed": func(flags *flag.FlagSet) {
+			flags.BoolVar(&cfg.ShowSuppressed, "show-suppressed", false, "report findings waived by inline secret-scan:ignore comments")
+		},
+		"exclude": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.Excludes}, "exclude", "gitignore style glob of paths to skip (repeatable, comma separated)")
+		},
+		"include": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.Includes}, "include", "gitignore style glob of files to scan, all others are skipped (repeatable, comma separated)")
+		},
+		"allowlist": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.Allowlist, verbatim: true}, "allowlist", "regular expression of secrets to ignore, such as example values (repeatable)")
+		},
+		"format": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.Format, "format", "json", "output format: json or sarif")
+		},
+		"redact": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.Redact, "redact", "none", "how secrets are written to findings and logs: none, partial or full")
+		},
+		"fail-on": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.FailOn, "fail-on", "low", "exit with status 1 when findings at or above this severity are reported: low, medium, high or critical")
+		},
+		"min-severity": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.MinSeverity, "min-severity", "low", "only load patterns at or above this severity")
+		},
+		"severity": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.Severities}, "severity", "only load patterns with these severities (repeatable, comma separated)")
+		},
+		"only-patterns": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.OnlyPatterns}, "only-patterns", "only load the named patterns (repeatable, comma separated)")
+		},
+		"skip-patterns": func(flags *flag.FlagSet) {
+			flags.Var(listFlag{values: &cfg.SkipPatterns}, "skip-patterns", "do not load the named patterns (repeatable, comma separated)")
+		},
+		"lenient-validators": func(flags *flag.FlagSet) {
+			flags.BoolVar(&cfg.LenientValidators, "lenient-validators", false, "disable patterns with unknown validators or parameters with a warning instead of failing")
+		},
+		"range": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.GitRange, "range", "", "git: revision range to scan such as main..HEAD (default all refs)")
+		},
+		"since": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.GitSince, "since", "", "git: only scan commits more recent than this date")
+		},
+		"force": func(flags *flag.FlagSet) {
+			flags.BoolVar(&cfg.Force, "force", false, "replace an existing pre-commit hook")
+		},
+		"json": func(flags *flag.FlagSet) { flags.BoolVar(&cfg.JSON, "json", false, "write JSON instead of a table") },
+		"baseline": func(flags *flag.FlagSet) {
+			flags.StringVar(&cfg.BaselinePath, "baseline", "", "only report findings not present in this baseline file")
+		},
+		"archive-depth": func(flags *flag.FlagSet) {
+			flags.IntVar(&cfg.ArchiveDepth, "archive-depth", 5, "maximum nesting depth of archives to scan, 0 disables archive scanning")
+		},
+		"archive-max-size": func(flags *flag.FlagSet) {
+			flags.Int64Var(&cfg.ArchiveMaxSize, "archive-max-size", 512, "maximum decompressed size in MB read from one archive")
+		},
+		"archive-max-ratio": func(flags *flag.FlagSet) {
+			flags.Float64Var(&cfg.ArchiveMaxRatio, "archive-max-ratio", 100, "maximum compression ratio of an archive member")
+		},
+	}
+}
@@ -18,48 +18,196 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
-	"runtime"
+	"strings"
 )
 
+// DefaultBaselineFile is written by "baseline create" when -out is not set
+const DefaultBaselineFile = "secret-scan-baseline.json"
+
 type Config struct {
+	Command           string
+	Args              []string // positional arguments following the flags
+	ConfigPath        string
 	Silent            bool
 	Verbose           bool
+	LogFile           string
+	LogFormat         string
 	NoDefaultPatterns bool
 	OutputFilename    string
 	ScanPath          string
 	PatternsPath      string
+	PluginsPath       string
 	Threads           int
+	Excludes          []string
+	Includes          []string
+	Allowlist         []string
+	ShowSuppressed    bool
+	BaselinePath      string
+	Format            string
+	Redact            string
+	FailOn            string
+	MinSeverity       string
+	Severities        []string
+	OnlyPatterns      []string
+	SkipPatterns      []string
+	LenientValidators bool
+	GitRange          string
+	GitSince          string
+	Force             bool
+	JSON              bool
+	ArchiveDepth      int
+	ArchiveMaxSize    int64
+	ArchiveMaxRatio   float64
+
+	// Validators replace the validators of the named patterns, they are only
+	// set by configuration files
+	Validators map[string]ValidatorOverride
+
+	// Sources records where the effective value of each setting came from
+	Sources []Source
+}
+
+// listFlag collects a repeatable, comma separated flag into a slice
+type listFlag struct {
+	values *[]string
+
+	// verbatim lists take each value whole, e.g. regular expressions which
+	// may contain commas
+	verbatim bool
+}
+
+func (l listFlag) String() string {
+	if l.values == nil {
+		return ""
+	}
+	return strings.Join(*l.values, ",")
+}
+
+// reset empties the list, a list set by a later configuration layer
+// replaces the earlier one
+func (l listFlag) reset() {
+	*l.values = nil
+}
+
+func (l listFlag) Set(value string) error {
+	if l.verbatim {
+		l.add(value)
+		return nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		l.add(item)
+	}
+	return nil
+}
+
+// add appends a single item without splitting it
+func (l listFlag) add(item string) {
+	if item = strings.TrimSpace(item); item != "" {
+		*l.values = append(*l.values, item)
+	}
+}
+
+// ParseFlags builds the configuration from the command line, configuration
+// files and SECRET_SCAN_* environment variables, see Load
+func ParseFlags() (Config, error) {
+	return Load(os.Args[1:])
 }
 
-func ParseFlags() Config {
-	cfg := Config{}
+// Load builds the configuration of the command named by args from defaults,
+// the user configuration file, the repository configuration file, the
+// -config file, SECRET_SCAN_* environment variables and the flags of the
+// command, each overriding the ones before
+func Load(args []string) (Config, error) {
+	command, args, err := FindCommand(args)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// the command line locates the configuration files
+	var cli Config
+	cliFlags := command.flagSet(&cli)
+	cliFlags.Parse(args)
+
+	cfg := Config{Validators: make(map[string]ValidatorOverride)}
+	flags := allFlags(&cfg)
+	sources := make(map[string]string)
+
+	scanPath := "."
+	if cliFlags.NArg() > 0 {
+		scanPath = cliFlags.Arg(0)
+	}
+
+	var layers []layer
+	if path := userConfigPath(); path != "" {
+		layers = append(layers, layer{path: path, origin: "user config " + path, optional: true})
+	}
+	if path := findRepoConfig(scanPath); path != "" {
+		layers = append(layers, layer{path: path, origin: "repo config " + path, untrusted: true})
+	}
+	if cli.ConfigPath != "" {
+		path := expandHome(cli.ConfigPath)
+		layers = append(layers, layer{path: path, origin: "config " + path})
+	}
+
+	for _, next := range layers {
+		if err := next.apply(flags, sources, cfg.Validators); err != nil {
+			return cfg, err
+		}
+	}
+	if err := applyEnv(flags, sources); err != nil {
+		return cfg, err
+	}
 
-	flag.BoolVar(&cfg.Silent, "silent", false, "suppress output")
-	flag.BoolVar(&cfg.Verbose, "verbose", false, "enable verbose output")
-	flag.BoolVar(&cfg.NoDefaultPatterns, "no-default-patterns", false, "disable loading of default patterns")
-	flag.StringVar(&cfg.OutputFilename, "out", "", "output file")
-	flag.StringVar(&cfg.PatternsPath, "patterns", "", "path to custome patterns file")
-	flag.IntVar(&cfg.Threads, "threads", runtime.NumCPU()-1, "number of threads")
+	// flags given on the command line override everything, lists replace
+	// rather than extend the configured ones
+	cliFlags.Visit(func(f *flag.Flag) {
+		target := flags.Lookup(f.Name)
+		if list, ok := f.Value.(listFlag); ok {
+			set(target, *list.values)
+		} else {
+			target.Value.Set(f.Value.String())
+		}
+		sources[f.Name] = "flag -" + f.Name
+	})
 
-	flag.Parse()
+	cfg.Command = command.Name
+	cfg.Args = cliFlags.Args()
+	cfg.ScanPath = scanPath
 
 	if cfg.Threads < 1 {
 		cfg.Threads = 1
 	}
 
-	if flag.NArg() > 0 {
-		cfg.ScanPath = flag.Arg(0)
-	} else {
-		cfg.ScanPath = "."
+	if cfg.ArchiveDepth < 0 {
+		cfg.ArchiveDepth = 0
 	}
 
 	// expand home path if supplied as part of the PatternsPath
-	if cfg.PatternsPath != "" {
-		if cfg.PatternsPath[:2] == "~/" {
-			home, _ := os.UserHomeDir()
-			cfg.PatternsPath = filepath.Join(home, cfg.PatternsPath[2:])
+	cfg.PatternsPath = expandHome(cfg.PatternsPath)
+	cfg.OutputFilename = expandHome(cfg.OutputFilename)
+	cfg.BaselinePath = expandHome(cfg.BaselinePath)
+	cfg.PluginsPath = expandHome(cfg.PluginsPath)
+	cfg.LogFile = expandHome(cfg.LogFile)
+
+	cfg.Sources = collectSources(flags, sources)
+
+	if cfg.Command == CommandBaselineCreate {
+		// a new baseline must contain every current finding
+		cfg.BaselinePath = ""
+		cfg.Format = "json"
+		if cfg.OutputFilename == "" {
+			cfg.OutputFilename = DefaultBaselineFile
 		}
 	}
 
-	return cfg
+	return cfg, nil
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[2:])
+	}
+	return path
 }
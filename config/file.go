@@ -0,0 +1,410 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is discovered from the scan root upward
+const RepoConfigFile = ".secret-scan.yaml"
+
+// EnvPrefix is prepended to the upper cased setting name of environment
+// variables, e.g. SECRET_SCAN_MIN_SEVERITY
+const EnvPrefix = "SECRET_SCAN_"
+
+// settings only accepted on the command line
+var commandLineOnly = map[string]bool{"config": true, "force": true, "json": true}
+
+// settings holding paths, which are relative to the configuration file
+// declaring them
+var pathSettings = map[string]bool{"out": true, "patterns": true, "plugins": true, "baseline": true, "log-file": true}
+
+// settings naming files which are written, a repository configuration comes
+// from the scanned and possibly untrusted tree and may not set them
+var outputSettings = map[string]bool{"out": true, "log-file": true}
+
+// validatorsKey configures the validators of patterns by name, it has no flag
+const validatorsKey = "validators"
+
+// Source describes the effective value of a setting and where it came from
+type Source struct {
+	Key    string   // configuration file key, the flag name with underscores
+	Values []string // one value unless List is set
+	List   bool
+	Origin string // default, a configuration file, an environment variable or a flag
+}
+
+// ValidatorSpec names a validator and its parameters
+type ValidatorSpec struct {
+	Name   string
+	Params map[string]any
+}
+
+// ValidatorOverride replaces the validators of a pattern, an empty list
+// disables validation of its matches
+type ValidatorOverride struct {
+	Validators []ValidatorSpec
+	Origin     string // the configuration file setting it
+}
+
+// settingKey converts a flag name into its configuration file key
+func settingKey(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// setting returns the flag configured by key, nil when there is none
+func setting(flags *flag.FlagSet, key string) *flag.Flag {
+	name := strings.ReplaceAll(key, "_", "-")
+	if commandLineOnly[name] {
+		return nil
+	}
+	return flags.Lookup(name)
+}
+
+// set replaces the value of a flag, lists take any number of values which
+// are used as given rather than split on commas
+func set(f *flag.Flag, values []string) error {
+	if list, ok := f.Value.(listFlag); ok {
+		list.reset()
+		for _, value := range values {
+			list.add(value)
+		}
+		return nil
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("expected a single value")
+	}
+	return f.Value.Set(values[0])
+}
+
+// layer is a configuration file
+type layer struct {
+	path      string
+	origin    string
+	optional  bool // missing files are skipped
+	untrusted bool // output settings are rejected
+}
+
+func (l layer) apply(flags *flag.FlagSet, sources map[string]string, overrides map[string]ValidatorOverride) error {
+	content, err := os.ReadFile(l.path)
+	if err != nil {
+		if l.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", l.origin, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("invalid %s: %w", l.origin, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == validatorsKey {
+			if err := parseValidators(values[key], l.origin, overrides); err != nil {
+				return fmt.Errorf("%s: %s: %w", l.origin, key, err)
+			}
+			continue
+		}
+
+		f := setting(flags, key)
+		if f == nil {
+			return fmt.Errorf("%s: unknown setting %q", l.origin, key)
+		}
+
+		if l.untrusted && outputSettings[f.Name] {
+			return fmt.Errorf("%s: %s may only be set in the user configuration, the -config file or a flag", l.origin, key)
+		}
+
+		items, err := yamlValues(values[key])
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", l.origin, key, err)
+		}
+
+		if pathSettings[f.Name] {
+			for index, item := range items {
+				if item != "" && !filepath.IsAbs(item) && !strings.HasPrefix(item, "~/") {
+					items[index] = filepath.Join(filepath.Dir(l.path), item)
+				}
+			}
+		}
+
+		if err := set(f, items); err != nil {
+			return fmt.Errorf("%s: %s: %w", l.origin, key, err)
+		}
+		sources[f.Name] = l.origin
+	}
+
+	return nil
+}
+
+// parseValidators reads a mapping of pattern names to lists of validators,
+// each a name or a mapping of name and parameters. Patterns configured by an
+// earlier layer are replaced.
+func parseValidators(value any, origin string, overrides map[string]ValidatorOverride) error {
+	patterns, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected a mapping of pattern names to validators")
+	}
+
+	for pattern, list := range patterns {
+		items, ok := list.([]any)
+		if !ok && list != nil {
+			return fmt.Errorf("%s: expected a list of validators", pattern)
+		}
+
+		override := ValidatorOverride{Validators: make([]ValidatorSpec, 0, len(items)), Origin: origin}
+		for _, item := range items {
+			switch v := item.(type) {
+			case string:
+				override.Validators = append(override.Validators, ValidatorSpec{Name: v})
+			case map[string]any:
+				name, ok := v["name"].(string)
+				if !ok || name == "" {
+					return fmt.Errorf("%s: validator requires a name", pattern)
+				}
+
+				spec := ValidatorSpec{Name: name}
+				for key, param := range v {
+					if key == "name" {
+						continue
+					}
+					if spec.Params == nil {
+						spec.Params = make(map[string]any)
+					}
+					spec.Params[key] = param
+				}
+				override.Validators = append(override.Validators, spec)
+			default:
+				return fmt.Errorf("%s: validators are names or mappings with a name", pattern)
+			}
+		}
+		overrides[pattern] = override
+	}
+
+	return nil
+}
+
+// yamlValues converts a scalar or a list of scalars into flag values
+func yamlValues(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return []string{""}, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []any, map[string]any:
+				return nil, fmt.Errorf("lists may only hold plain values")
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, nil
+	case map[string]any:
+		return nil, fmt.Errorf("expected a value or a list, not a mapping")
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// applyEnv applies the SECRET_SCAN_* environment variables, lists are comma
+// separated except for verbatim lists, which take a single value
+func applyEnv(flags *flag.FlagSet, sources map[string]string) error {
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if err != nil || commandLineOnly[f.Name] {
+			return
+		}
+
+		name := EnvPrefix + strings.ToUpper(settingKey(f.Name))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if list, isList := f.Value.(listFlag); isList {
+			list.reset()
+		}
+		if setErr := f.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", name, setErr)
+			return
+		}
+		sources[f.Name] = "env " + name
+	})
+	return err
+}
+
+// userConfigPath returns the path of the user configuration file, in
+// $XDG_CONFIG_HOME or ~/.config
+func userConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "secret-scan", "config.yaml")
+}
+
+// findRepoConfig returns the nearest RepoConfigFile in the scan root or its
+// parents, empty when there is none
+func findRepoConfig(scanPath string) string {
+	dir, err := filepath.Abs(scanPath)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		path := filepath.Join(dir, RepoConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// collectSources records the effective value of every setting
+func collectSources(flags *flag.FlagSet, origins map[string]string) []Source {
+	var sources []Source
+	flags.VisitAll(func(f *flag.Flag) {
+		if commandLineOnly[f.Name] {
+			return
+		}
+
+		source := Source{Key: settingKey(f.Name), Origin: origins[f.Name]}
+		if source.Origin == "" {
+			source.Origin = "default"
+		}
+
+		if list, ok := f.Value.(listFlag); ok {
+			source.List = true
+			source.Values = append([]string{}, *list.values...)
+		} else {
+			source.Values = []string{f.Value.String()}
+		}
+		sources = append(sources, source)
+	})
+	return sources
+}
+
+// Print writes the effective configuration as YAML, each setting commented
+// with where its value came from
+func (c Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, source := range c.Sources {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: source.Key}
+
+		var value *yaml.Node
+		if source.List {
+			value = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			for _, item := range source.Values {
+				value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		} else {
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: source.Values[0]}
+		}
+		value.LineComment = source.Origin
+
+		root.Content = append(root.Content, key, value)
+	}
+
+	if len(c.Validators) > 0 {
+		validators := &yaml.Node{Kind: yaml.MappingNode}
+
+		patterns := make([]string, 0, len(c.Validators))
+		for pattern := range c.Validators {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+
+		for _, pattern := range patterns {
+			override := c.Validators[pattern]
+			list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, LineComment: override.Origin}
+			for _, spec := range override.Validators {
+				item, err := spec.node()
+				if err != nil {
+					return err
+				}
+				list.Content = append(list.Content, item)
+			}
+			validators.Content = append(validators.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: pattern}, list)
+		}
+
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: validatorsKey}, validators)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// node converts a validator into its configuration file form
+func (v ValidatorSpec) node() (*yaml.Node, error) {
+	if len(v.Params) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: v.Name}, nil
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "name"},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: v.Name})
+
+	keys := make([]string, 0, len(v.Params))
+	for key := range v.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := &yaml.Node{}
+		if err := value.Encode(v.Params[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return node, nil
+}
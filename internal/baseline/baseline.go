@@ -0,0 +1,88 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package baseline
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Baseline holds the fingerprints of previously accepted findings
+type Baseline struct {
+	fingerprints map[string]struct{}
+}
+
+type entry struct {
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Load reads the NDJSON findings stream written by a previous scan
+func Load(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline: %w", err)
+	}
+	defer f.Close()
+
+	b := &Baseline{fingerprints: make(map[string]struct{})}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var next entry
+		if err := json.Unmarshal([]byte(line), &next); err != nil {
+			return nil, fmt.Errorf("invalid baseline entry on line %d: %w", lineNum, err)
+		}
+		if next.Fingerprint == "" {
+			return nil, fmt.Errorf("baseline entry on line %d has no fingerprint, regenerate it with 'baseline create'", lineNum)
+		}
+
+		b.fingerprints[next.Fingerprint] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	return b, nil
+}
+
+// Contains reports whether a finding fingerprint was present in the baseline
+func (b *Baseline) Contains(fingerprint string) bool {
+	if b == nil {
+		return false
+	}
+	_, ok := b.fingerprints[fingerprint]
+	return ok
+}
+
+func (b *Baseline) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.fingerprints)
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractors
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrArchiveLimit is returned when an archive exceeds the configured limits
+var ErrArchiveLimit = errors.New("archive limit exceeded")
+
+// Container expands an archive or compressed file into its members, each of
+// which is classified and extracted with the registry like a file on disk
+type Container interface {
+	// Supports return true if a file type is supported. The filename is
+	// only a hint, sample holds the leading bytes of the file content.
+	Supports(filename string, sample []byte) bool
+
+	// Members calls fn for each member in turn, a member reader is only
+	// valid until fn returns. Reads are accounted against budget.
+	Members(ctx context.Context, r io.Reader, budget *Budget, fn func(member Member) error) error
+}
+
+// Member is a file inside a container
+type Member struct {
+	Name   string
+	Reader io.Reader
+
+	// Transparent is set for the decompressed content of a single file
+	// compression format, which is reported under the container path. Name
+	// may be empty, see DecompressedName.
+	Transparent bool
+
+	// Skipped is set instead of Reader when the member is not read because
+	// it exceeds a limit, the remaining members are still read
+	Skipped error
+}
+
+type ArchiveLimits struct {
+	MaxDepth     int     // nesting depth of containers, 0 disables archive scanning
+	MaxTotalSize int64   // decompressed bytes read from one top level archive
+	MaxRatio     float64 // decompressed to compressed size ratio of a member
+}
+
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxDepth:     5,
+	MaxTotalSize: 512 * 1024 * 1024, // 512mb
+	MaxRatio:     100,
+}
+
+// ratioGrace is the amount of decompressed data read before the ratio check
+// applies, small files of repeated content legitimately compress very well
+const ratioGrace = 1024 * 1024 // 1mb
+
+// Budget accounts the bytes of the members extracted from a top level archive
+// and all of its nested archives. Only the content handed to extractors is
+// charged, so every decompressed byte counts once whatever the nesting. It is
+// not safe for concurrent use.
+type Budget struct {
+	limits ArchiveLimits
+	used   int64
+}
+
+func NewBudget(limits ArchiveLimits) *Budget {
+	return &Budget{limits: limits}
+}
+
+func (b *Budget) Limits() ArchiveLimits {
+	return b.limits
+}
+
+// Charge wraps the content of a member which is extracted rather than
+// expanded as a nested archive, failing with ErrArchiveLimit once the bytes
+// read from all such members exceed the total size limit
+func (b *Budget) Charge(r io.Reader) io.Reader {
+	return &chargedReader{budget: b, r: r}
+}
+
+type chargedReader struct {
+	budget *Budget
+	r      io.Reader
+}
+
+func (cr *chargedReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.budget.used += int64(n)
+
+	if limitErr := cr.budget.Err(); limitErr != nil {
+		return n, limitErr
+	}
+	return n, err
+}
+
+// Err returns an ErrArchiveLimit error once the total size is exceeded
+func (b *Budget) Err() error {
+	if b.limits.MaxTotalSize > 0 && b.used > b.limits.MaxTotalSize {
+		return fmt.Errorf("%w: more than %d bytes decompressed", ErrArchiveLimit, b.limits.MaxTotalSize)
+	}
+	return nil
+}
+
+// reader wraps the decompressed stream of a member, failing with
+// ErrArchiveLimit once its compression ratio is exceeded. compressed reports
+// the compressed bytes consumed so far. The total size is charged by Charge.
+func (b *Budget) reader(r io.Reader, compressed func() int64) io.Reader {
+	return &ratioReader{limits: b.limits, r: r, compressed: compressed}
+}
+
+type ratioReader struct {
+	limits     ArchiveLimits
+	r          io.Reader
+	read       int64
+	compressed func() int64
+}
+
+func (br *ratioReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.read += int64(n)
+
+	limits := br.limits
+	if limits.MaxRatio > 0 && br.read > ratioGrace {
+		compressed := br.compressed()
+		if compressed >= 0 && float64(br.read) > limits.MaxRatio*float64(max(compressed, 1)) {
+			return n, fmt.Errorf("%w: compression ratio above %.0f", ErrArchiveLimit, limits.MaxRatio)
+		}
+	}
+
+	return n, err
+}
+
+// countingReader counts the compressed bytes consumed by a decompressor
+type countingReader struct {
+	r     io.Reader
+	count int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+func hasExtension(filename string, extensions ...string) bool {
+	lower := strings.ToLower(filename)
+	for _, extension := range extensions {
+		if strings.HasSuffix(lower, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// ZipContainer reads zip based archives such as .jar and .war
+type ZipContainer struct{}
+
+func (z *ZipContainer) Supports(filename string, sample []byte) bool {
+	return hasExtension(filename, ".zip", ".jar", ".war", ".ear") &&
+		(HasMagic(sample, []byte("PK\x03\x04")) || HasMagic(sample, []byte("PK\x05\x06")))
+}
+
+func (z *ZipContainer) Members(ctx context.Context, r io.Reader, budget *Budget, fn func(member Member) error) error {
+	readerAt, size, err := toReaderAt(r, budget)
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range archive.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		// skip declared bombs without inflating them
+		limits := budget.limits
+		if limits.MaxRatio > 0 && file.UncompressedSize64 > ratioGrace &&
+			float64(file.UncompressedSize64) > limits.MaxRatio*float64(max(file.CompressedSize64, 1)) {
+			err := fmt.Errorf("%w: compression ratio above %.0f", ErrArchiveLimit, limits.MaxRatio)
+			if err := fn(Member{Name: file.Name, Skipped: err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+
+		compressedSize := int64(file.CompressedSize64)
+		err = fn(Member{
+			Name:   file.Name,
+			Reader: budget.reader(content, func() int64 { return compressedSize }),
+		})
+		content.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toReaderAt returns r as a random access reader, buffering nested archives
+// in memory. A nested archive is not charged to the budget, only its members
+// are, but it may not be larger than the remaining budget.
+func toReaderAt(r io.Reader, budget *Budget) (io.ReaderAt, int64, error) {
+	if f, ok := r.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+
+	limit := budget.limits.MaxTotalSize - budget.used
+	if budget.limits.MaxTotalSize <= 0 {
+		limit = DefaultArchiveLimits.MaxTotalSize
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(content)) > limit {
+		return nil, 0, fmt.Errorf("%w: nested archive larger than remaining budget", ErrArchiveLimit)
+	}
+
+	return bytes.NewReader(content), int64(len(content)), nil
+}
+
+// TarContainer reads uncompressed tar archives, compressed tarballs are
+// handled by nesting within GzipContainer or Bzip2Container
+type TarContainer struct{}
+
+func (t *TarContainer) Supports(filename string, sample []byte) bool {
+	// ustar magic at offset 257
+	if len(sample) >= 262 && string(sample[257:262]) == "ustar" {
+		return true
+	}
+	return hasExtension(filename, ".tar") && len(sample) >= 512 && bytes.IndexByte(sample[:100], 0x00) > 0
+}
+
+func (t *TarContainer) Members(ctx context.Context, r io.Reader, budget *Budget, fn func(member Member) error) error {
+	archive := tar.NewReader(r)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// tar does not compress, the ratio is enforced by an outer container
+		if err := fn(Member{Name: header.Name, Reader: archive}); err != nil {
+			return err
+		}
+	}
+}
+
+// GzipContainer decompresses .gz files, including .tar.gz and .tgz
+type GzipContainer struct{}
+
+func (g *GzipContainer) Supports(filename string, sample []byte) bool {
+	return HasMagic(sample, []byte("\x1F\x8B\x08"))
+}
+
+func (g *GzipContainer) Members(ctx context.Context, r io.Reader, budget *Budget, fn func(member Member) error) error {
+	compressed := &countingReader{r: r}
+
+	stream, err := gzip.NewReader(compressed)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// the original name is only present when stored by the compressor
+	name := ""
+	if stream.Name != "" {
+		name = filepath.Base(stream.Name)
+	}
+
+	return fn(Member{
+		Name:        name,
+		Reader:      budget.reader(stream, func() int64 { return compressed.count }),
+		Transparent: true,
+	})
+}
+
+// Bzip2Container decompresses .bz2 files, including .tar.bz2 and .tbz2
+type Bzip2Container struct{}
+
+func (b *Bzip2Container) Supports(filename string, sample []byte) bool {
+	return len(sample) >= 10 && HasMagic(sample, []byte("BZh")) &&
+		sample[3] >= '1' && sample[3] <= '9' &&
+		(string(sample[4:10]) == "\x31\x41\x59\x26\x53\x59" || string(sample[4:10]) == "\x17\x72\x45\x38\x50\x90")
+}
+
+func (b *Bzip2Container) Members(ctx context.Context, r io.Reader, budget *Budget, fn func(member Member) error) error {
+	compressed := &countingReader{r: r}
+
+	return fn(Member{
+		Reader:      budget.reader(bzip2.NewReader(compressed), func() int64 { return compressed.count }),
+		Transparent: true,
+	})
+}
+
+// DecompressedName derives the name of the content of a compressed file,
+// e.g. "app.log.gz" becomes "app.log" and "release.tgz" becomes "release.tar"
+func DecompressedName(name string) string {
+	lower := strings.ToLower(name)
+	for extension, replacement := range map[string]string{".tgz": ".tar", ".tbz2": ".tar", ".gz": "", ".bz2": ""} {
+		if strings.HasSuffix(lower, extension) {
+			return name[:len(name)-len(extension)] + replacement
+		}
+	}
+	return name
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractors
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// SampleSize is the number of leading bytes read to classify a file
+const SampleSize = 8 * 1024
+
+// Encoding identifies the character encoding of a plaintext sample
+type Encoding int
+
+const (
+	EncodingBinary Encoding = iota
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+)
+
+func (e Encoding) IsText() bool {
+	return e != EncodingBinary
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32LE = []byte{0xFF, 0xFE, 0x00, 0x00}
+	bomUTF32BE = []byte{0x00, 0x00, 0xFE, 0xFF}
+)
+
+// binaryMagic holds signatures of common binary formats which may otherwise
+// pass the control character heuristics (e.g. short headers followed by text)
+var binaryMagic = [][]byte{
+	[]byte("\x7FELF"),             // ELF executables
+	[]byte("\xCA\xFE\xBA\xBE"),    // java class, mach-o fat binary
+	[]byte("\xCF\xFA\xED\xFE"),    // mach-o 64-bit
+	[]byte("\xCE\xFA\xED\xFE"),    // mach-o 32-bit
+	[]byte("\x89PNG\r\n\x1A\n"),   // png
+	[]byte("\xFF\xD8\xFF"),        // jpeg
+	[]byte("GIF87a"),              // gif
+	[]byte("GIF89a"),              // gif
+	[]byte("%PDF-"),               // pdf
+	[]byte("PK\x03\x04"),          // zip, jar, docx, xlsx
+	[]byte("PK\x05\x06"),          // empty zip
+	[]byte("\x1F\x8B"),            // gzip
+	[]byte("\xFD7zXZ\x00"),        // xz
+	[]byte("7z\xBC\xAF\x27\x1C"),  // 7-zip
+	[]byte("Rar!\x1A\x07"),        // rar
+	[]byte("SQLite format 3\x00"), // sqlite
+	[]byte("\x00asm"),             // wasm
+	[]byte("\xD0\xCF\x11\xE0"),    // ole2 (xls, doc, msi)
+}
+
+// HasMagic reports whether sample begins with the given signature
+func HasMagic(sample []byte, magic []byte) bool {
+	return bytes.HasPrefix(sample, magic)
+}
+
+// DetectEncoding classifies a leading sample of file content as binary or as
+// plaintext in one of the supported encodings. An empty sample is treated as
+// UTF-8 text.
+func DetectEncoding(sample []byte) Encoding {
+	// byte order marks are authoritative
+	switch {
+	case bytes.HasPrefix(sample, bomUTF32LE), bytes.HasPrefix(sample, bomUTF32BE):
+		return EncodingBinary // utf-32 is not supported
+	case bytes.HasPrefix(sample, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+
+	if hasBinaryMagic(sample) {
+		return EncodingBinary
+	}
+
+	if len(sample) == 0 {
+		return EncodingUTF8
+	}
+
+	if bytes.IndexByte(sample, 0x00) >= 0 {
+		return detectUTF16(sample)
+	}
+
+	if utf8.Valid(trimPartialRune(sample)) {
+		if controlRatio(sample) > 0.1 {
+			return EncodingBinary
+		}
+		return EncodingUTF8
+	}
+
+	// not valid utf-8, but may still be a legacy single byte encoding
+	// such as latin-1; only accept it if it is mostly printable
+	if controlRatio(sample) > 0.1 || highBitRatio(sample) > 0.3 {
+		return EncodingBinary
+	}
+	return EncodingUTF8
+}
+
+func hasBinaryMagic(sample []byte) bool {
+	for _, magic := range binaryMagic {
+		if HasMagic(sample, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectUTF16 recognises BOM-less utf-16 by the alternating NUL bytes of
+// ascii-range characters. Anything else containing NUL is binary.
+func detectUTF16(sample []byte) Encoding {
+	pairs := len(sample) / 2
+	if pairs == 0 {
+		return EncodingBinary
+	}
+
+	var evenNul, oddNul int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0x00 {
+			evenNul++
+		}
+		if sample[i+1] == 0x00 {
+			oddNul++
+		}
+	}
+
+	const threshold = 0.7
+	switch {
+	case float64(oddNul)/float64(pairs) > threshold && evenNul == 0:
+		return EncodingUTF16LE
+	case float64(evenNul)/float64(pairs) > threshold && oddNul == 0:
+		return EncodingUTF16BE
+	default:
+		return EncodingBinary
+	}
+}
+
+// trimPartialRune drops an incomplete multibyte sequence cut off at the end of a sample
+func trimPartialRune(sample []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				return sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	return sample
+}
+
+// controlRatio returns the fraction of bytes that are control characters not
+// normally found in text files
+func controlRatio(sample []byte) float64 {
+	var count int
+	for _, b := range sample {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != '\v' && b != 0x1B {
+			count++
+		} else if b == 0x7F {
+			count++
+		}
+	}
+	return float64(count) / float64(len(sample))
+}
+
+func highBitRatio(sample []byte) float64 {
+	var count int
+	for _, b := range sample {
+		if b >= 0x80 {
+			count++
+		}
+	}
+	return float64(count) / float64(len(sample))
+}
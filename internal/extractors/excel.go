@@ -16,42 +16,99 @@ package extractors
 
 import (
 	"context"
+	"github.com/clarityoverclever/secret-scan/internal/models"
 	"io"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/xuri/excelize/v2"
 )
 
 type ExcelExtractor struct{}
 
-func (e *ExcelExtractor) Supports(filename string) bool {
+func (e *ExcelExtractor) Supports(filename string, sample []byte) bool {
 	extension := strings.ToLower(filepath.Ext(filename))
-	return extension == ".xlsx"
+	return extension == ".xlsx" && HasMagic(sample, []byte("PK\x03\x04"))
 }
 
-func (e *ExcelExtractor) Extract(ctx context.Context, r io.Reader) ([]string, error) {
+func (e *ExcelExtractor) Extract(ctx context.Context, r io.Reader, emit func(line Line) error) error {
+	// the workbook is opened in memory, rows of each sheet are then streamed
 	f, err := excelize.OpenReader(r)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer f.Close()
 
-	var lines []string
-
 	for _, sheet := range f.GetSheetList() {
-		rows, err := f.GetRows(sheet)
+		if err := e.extractSheet(ctx, f, sheet, emit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractSheet emits each row of a sheet as a tab separated line numbered by
+// its row, with a region per cell so findings can name the cell
+func (e *ExcelExtractor) extractSheet(ctx context.Context, f *excelize.File, sheet string, emit func(line Line) error) error {
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	number := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		number++
+
+		row, err := rows.Columns()
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		var line strings.Builder
+		var regions []Region
+		for index, value := range row {
+			if index > 0 {
+				line.WriteByte('\t') // tab separated
+			}
+			if value == "" {
+				continue
+			}
+
+			cell, err := excelize.CoordinatesToCellName(index+1, number)
+			if err != nil {
+				return err
+			}
+			regions = append(regions, Region{
+				Start:    line.Len(),
+				Location: models.Location{Sheet: sheet, Cell: cellReference(sheet, cell)},
+			})
+			line.WriteString(value)
 		}
 
-		for _, row := range rows {
-			line := strings.Join(row, "\t") // tab separated
-			if strings.TrimSpace(line) != " " {
-				lines = append(lines, line)
+		for _, window := range SplitLine(number, line.String()) {
+			window.Regions = regions
+			if err := emit(window); err != nil {
+				return err
 			}
 		}
 	}
 
-	return lines, nil
+	return rows.Error()
+}
+
+// cellReference qualifies a cell with its sheet as spreadsheet formulas do,
+// e.g. Sheet2!C14 or 'Q1 Budget'!A1
+func cellReference(sheet string, cell string) string {
+	if strings.ContainsFunc(sheet, func(r rune) bool {
+		return !(r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}) {
+		sheet = "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+	}
+	return sheet + "!" + cell
 }
@@ -17,18 +17,26 @@ package extractors
 import (
 	"context"
 	"io"
+	"os"
+	"slices"
 )
 
 // Extractor converts file content into scannable unicode
 type Extractor interface {
-	Extract(ctx context.Context, r io.Reader) ([]string, error)
+	// Extract streams the lines of the content to emit in order, stopping
+	// at the first error returned by emit. Lines emitted before an error
+	// remain valid.
+	Extract(ctx context.Context, r io.Reader, emit func(line Line) error) error
 
-	// Supports return true if a file type is supported
-	Supports(filename string) bool
+	// Supports return true if a file type is supported. The filename is
+	// only a hint, sample holds the leading bytes of the file content.
+	Supports(filename string, sample []byte) bool
 }
 
 type Registry struct {
 	extractors []Extractor
+	containers []Container
+	registered int // extractors added with Register, at the front
 }
 
 func NewRegistry() *Registry {
@@ -37,12 +45,61 @@ func NewRegistry() *Registry {
 			&TextExtractor{},
 			&ExcelExtractor{},
 		},
+		containers: []Container{
+			&ZipContainer{},
+			&TarContainer{},
+			&GzipContainer{},
+			&Bzip2Container{},
+		},
+	}
+}
+
+// Register adds an extractor, such as a plugin, which is consulted before the
+// built-in ones. Extractors registered earlier take precedence.
+func (r *Registry) Register(extractor Extractor) {
+	r.extractors = slices.Insert(r.extractors, r.registered, extractor)
+	r.registered++
+}
+
+// ReadSample returns the leading bytes of the file at path used to classify it
+func ReadSample(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sample := make([]byte, SampleSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return sample[:n], nil
+}
+
+// MatchContainer returns the first container format supporting the given
+// name and content sample. Registered extractors take precedence, nil is
+// returned when one of them supports the file.
+func (r *Registry) MatchContainer(filename string, sample []byte) Container {
+	for _, extractor := range r.extractors[:r.registered] {
+		if extractor.Supports(filename, sample) {
+			return nil
+		}
+	}
+
+	for _, container := range r.containers {
+		if container.Supports(filename, sample) {
+			return container
+		}
 	}
+	return nil
 }
 
-func (r *Registry) Get(filename string) Extractor {
+// Match returns the first extractor supporting the given name and content sample
+func (r *Registry) Match(filename string, sample []byte) Extractor {
 	for _, extractor := range r.extractors {
-		if extractor.Supports(filename) {
+		if extractor.Supports(filename, sample) {
 			return extractor
 		}
 	}
@@ -0,0 +1,149 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extractors
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	// WindowSize is the longest text scanned at once, longer lines are split
+	// into overlapping windows
+	WindowSize = 64 * 1024 // 64kb
+
+	// WindowOverlap is repeated at the start of the next window so a match
+	// crossing a window boundary is still seen whole. It must be longer than
+	// any secret a pattern is expected to match.
+	WindowOverlap = 4 * 1024 // 4kb
+)
+
+// Line is a line of extracted content. Lines longer than WindowSize are
+// returned as several windows sharing the same line number.
+type Line struct {
+	Number int    // 1 based line number
+	Text   string // the line, or one window of it
+	Offset int    // byte offset of Text within the full line
+	Start  int64  // byte offset of the full line within the content, -1 if unknown
+
+	// Overlap is the number of leading bytes of Text already contained in
+	// the previous window, matches ending within them were already reported
+	Overlap int
+
+	// Location optionally holds extractor specific coordinates of the line
+	Location models.Location
+
+	// Regions optionally divide the full line into parts with their own
+	// location, such as the cells of a spreadsheet row
+	Regions []Region
+}
+
+// Region is a part of a line starting at byte offset Start of the full line
+// and ending where the next region starts
+type Region struct {
+	Start    int
+	Location models.Location
+}
+
+// Region returns the region containing the byte offset of the full line, or
+// nil if the line has no regions
+func (l Line) Region(offset int) *Region {
+	index := sort.Search(len(l.Regions), func(i int) bool {
+		return l.Regions[i].Start > offset
+	})
+	if index == 0 {
+		return nil
+	}
+	return &l.Regions[index-1]
+}
+
+// SplitLine returns text as a single Line, or as overlapping windows when it
+// is longer than WindowSize. The offset of the line in its content is unknown.
+func SplitLine(number int, text string) []Line {
+	var lines []Line
+	w := windower{emit: func(line Line) error {
+		lines = append(lines, line)
+		return nil
+	}}
+
+	w.start(number, -1)
+	_ = w.write([]byte(text))
+	_ = w.end()
+	return lines
+}
+
+// windower splits a line written in pieces into overlapping windows without
+// holding more than one window of it in memory
+type windower struct {
+	emit    func(line Line) error
+	number  int
+	lineAt  int64
+	buf     []byte
+	offset  int
+	overlap int
+	split   bool // at least one window of the current line was emitted
+}
+
+func (w *windower) start(number int, lineAt int64) {
+	w.number = number
+	w.lineAt = lineAt
+	w.buf = w.buf[:0]
+	w.offset = 0
+	w.overlap = 0
+	w.split = false
+}
+
+func (w *windower) write(p []byte) error {
+	w.buf = append(w.buf, p...)
+
+	for len(w.buf) > WindowSize {
+		// never cut a multi byte character in half
+		cut := len(trimPartialRune(w.buf[:WindowSize]))
+		if err := w.flush(w.buf[:cut]); err != nil {
+			return err
+		}
+
+		keep := cut - WindowOverlap
+		for keep < cut && !utf8.RuneStart(w.buf[keep]) {
+			keep++
+		}
+
+		w.offset += keep
+		w.overlap = cut - keep
+		w.buf = append(w.buf[:0], w.buf[keep:]...)
+		w.split = true
+	}
+	return nil
+}
+
+// end emits the remainder of the current line
+func (w *windower) end() error {
+	// the tail is already covered when the line ended on a window boundary
+	if w.split && len(w.buf) <= w.overlap {
+		return nil
+	}
+	return w.flush(w.buf)
+}
+
+func (w *windower) flush(text []byte) error {
+	return w.emit(Line{
+		Number:  w.number,
+		Text:    string(text),
+		Offset:  w.offset,
+		Start:   w.lineAt,
+		Overlap: w.overlap,
+	})
+}
@@ -16,18 +16,33 @@ package extractors
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"io"
 	"path/filepath"
 	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
 )
 
 type TextExtractor struct{}
 
-func (e *TextExtractor) Supports(filename string) bool {
-	extension := strings.ToLower(filepath.Ext(filename))
-	textExtensions := []string{".txt", ".log", ".yaml", ".yml", ".json", ".md", ".conf", ".cfg", ".csv"}
+// textExtensions are only used as a hint for files in legacy encodings
+// which fail content detection
+var textExtensions = []string{".txt", ".log", ".yaml", ".yml", ".json", ".md", ".conf", ".cfg", ".csv"}
+
+func (e *TextExtractor) Supports(filename string, sample []byte) bool {
+	if DetectEncoding(sample).IsText() {
+		return true
+	}
 
+	// never accept content containing NUL bytes or a binary signature
+	if bytes.IndexByte(sample, 0x00) >= 0 || hasBinaryMagic(sample) {
+		return false
+	}
+
+	extension := strings.ToLower(filepath.Ext(filename))
 	for _, supportedExtension := range textExtensions {
 		if extension == supportedExtension {
 			return true
@@ -36,35 +51,92 @@ func (e *TextExtractor) Supports(filename string) bool {
 	return false
 }
 
-func (e *TextExtractor) Extract(ctx context.Context, r io.Reader) ([]string, error) {
-	var lines []string
-	scanner := bufio.NewScanner(r)
+func (e *TextExtractor) Extract(ctx context.Context, r io.Reader, emit func(line Line) error) error {
+	decoded, consumed, err := decodeText(r)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(decoded, WindowSize)
+
+	// offsets in transcoded text are not offsets in the file
+	lineAt := func() int64 {
+		if consumed < 0 {
+			return -1
+		}
+		return consumed
+	}
 
-	// bufio.Scanner has a maximum line length of 64kb per token
-	// this will limit the line length the scanner will read to
-	// prevent errors on lines which exceed 64kb.
+	// lines of any length are read in pieces, long lines become windows, so
+	// memory is bounded by the window size rather than the file size
+	w := windower{emit: emit}
+	w.start(1, lineAt())
 
-	const maxLineLength = 10 * 1024 // 10kb
-	buf := make([]byte, maxLineLength)
-	scanner.Buffer(buf, maxLineLength)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+		piece, readErr := reader.ReadSlice('\n')
+		if consumed >= 0 {
+			consumed += int64(len(piece))
+		}
 
-		if len(line) < maxLineLength {
-			lines = append(lines, line)
+		if readErr == bufio.ErrBufferFull {
+			if err := w.write(piece); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// ignore "token too long" errors
-		if err := scanner.Err(); err != nil {
-			if strings.Contains(err.Error(), "token too long") {
-				return lines, nil
+		if len(piece) > 0 || w.split || len(w.buf) > 0 {
+			if err := w.write(bytes.TrimSuffix(piece, []byte("\n"))); err != nil {
+				return err
+			}
+			// drop the carriage return of a crlf line ending
+			if n := len(w.buf); n > 0 && w.buf[n-1] == '\r' {
+				w.buf = w.buf[:n-1]
 			}
+			if err := w.end(); err != nil {
+				return err
+			}
+			w.start(w.number+1, lineAt())
+		}
 
-			// return other errors
-			return lines, scanner.Err()
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
 		}
 	}
+}
+
+// decodeText wraps r so that utf-16 content is transcoded to utf-8 and any
+// byte order mark is removed. It also returns the offset in r of the first
+// byte read, -1 when the text is transcoded and offsets in it do not
+// correspond to offsets in r.
+func decodeText(r io.Reader) (io.Reader, int64, error) {
+	buffered := bufio.NewReaderSize(r, SampleSize)
 
-	return lines, nil
+	sample, err := buffered.Peek(SampleSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, 0, err
+	}
+
+	switch DetectEncoding(sample) {
+	case EncodingUTF16LE:
+		decoder := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+		return transform.NewReader(buffered, decoder), -1, nil
+	case EncodingUTF16BE:
+		decoder := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+		return transform.NewReader(buffered, decoder), -1, nil
+	}
+
+	if bytes.HasPrefix(sample, bomUTF8) {
+		if _, err := buffered.Discard(len(bomUTF8)); err != nil {
+			return nil, 0, err
+		}
+		return buffered, int64(len(bomUTF8)), nil
+	}
+	return buffered, 0, nil
 }
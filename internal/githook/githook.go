@@ -0,0 +1,89 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githook
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// marker identifies hooks written by Install so they may be replaced
+const marker = "# installed by secret-scan hook install"
+
+const hookName = "pre-commit"
+
+// Install writes a pre-commit hook into the repository at repo which runs
+// the staged scan with the given executable. An existing hook not written by
+// Install is only replaced when force is set. The hook path is returned.
+func Install(repo string, executable string, force bool) (string, error) {
+	hooksDir, err := hooksPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(hooksDir, hookName)
+
+	existing, err := os.ReadFile(path)
+	if err == nil && !force && !bytes.Contains(existing, []byte(marker)) {
+		return "", fmt.Errorf("%s already exists, use -force to replace it", path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read existing hook: %w", err)
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec %s staged\n", marker, shellQuote(executable))
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to make hook executable: %w", err)
+	}
+
+	return path, nil
+}
+
+// hooksPath asks git for the hooks directory so core.hooksPath and linked
+// worktrees are honored
+func hooksPath(repo string) (string, error) {
+	cmd := exec.Command("git", "-C", repo, "rev-parse", "--git-path", "hooks")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git hooks directory: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repo, path)
+	}
+	return path, nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -16,63 +16,112 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 )
 
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var Formats = []string{FormatText, FormatJSON}
+
+// Options configures the diagnostics of a run. Diagnostics never go to
+// stdout, which is reserved for findings.
+type Options struct {
+	Silent  bool
+	Verbose bool
+	Format  string // text or json, text when empty
+
+	// File receives all records when set, stderr then only receives
+	// warnings and errors
+	File io.Writer
+}
+
+// MultiHandler passes each record to every handler enabled for its level
 type MultiHandler struct {
-	stdoutHandler slog.Handler
-	stderrHandler slog.Handler
-	threshold     slog.Level
+	handlers []slog.Handler
+}
+
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
 }
 
 func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return h.stdoutHandler.Enabled(ctx, level) || h.stderrHandler.Enabled(ctx, level)
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
 }
 
 func (h *MultiHandler) Handle(ctx context.Context, record slog.Record) error {
-	if record.Level >= h.threshold {
-		return h.stderrHandler.Handle(ctx, record)
+	var firstErr error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	return h.stdoutHandler.Handle(ctx, record)
+	return firstErr
 }
 
 func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &MultiHandler{
-		stdoutHandler: h.stdoutHandler.WithAttrs(attrs),
-		stderrHandler: h.stderrHandler.WithAttrs(attrs),
-		threshold:     h.threshold,
+	handlers := make([]slog.Handler, len(h.handlers))
+	for index, handler := range h.handlers {
+		handlers[index] = handler.WithAttrs(attrs)
 	}
+	return &MultiHandler{handlers: handlers}
 }
 
 func (h *MultiHandler) WithGroup(name string) slog.Handler {
-	return &MultiHandler{
-		stdoutHandler: h.stdoutHandler.WithGroup(name),
-		stderrHandler: h.stderrHandler.WithGroup(name),
+	handlers := make([]slog.Handler, len(h.handlers))
+	for index, handler := range h.handlers {
+		handlers[index] = handler.WithGroup(name)
 	}
+	return &MultiHandler{handlers: handlers}
 }
 
-func SetupLogger(silent, verbose bool) *slog.Logger {
+func SetupLogger(opts Options) (*slog.Logger, error) {
+	switch opts.Format {
+	case FormatText, FormatJSON, "":
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected one of %v", opts.Format, Formats)
+	}
+
 	var level slog.Level
 
 	switch {
-	case silent:
+	case opts.Silent:
 		level = slog.LevelError
-	case verbose:
+	case opts.Verbose:
 		level = slog.LevelDebug
 	default:
 		level = slog.LevelInfo
 	}
 
-	handler := &MultiHandler{
-		stdoutHandler: slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
-		}),
-		stderrHandler: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-			Level: level,
-		}),
-		threshold: slog.LevelWarn,
+	newHandler := func(w io.Writer, level slog.Level) slog.Handler {
+		options := &slog.HandlerOptions{Level: level}
+		if opts.Format == FormatJSON {
+			return slog.NewJSONHandler(w, options)
+		}
+		return slog.NewTextHandler(w, options)
+	}
+
+	if opts.File == nil {
+		return slog.New(newHandler(os.Stderr, level)), nil
 	}
 
-	return slog.New(handler)
+	// problems stay visible on the terminal when logging to a file
+	handler := NewMultiHandler(
+		newHandler(opts.File, level),
+		newHandler(os.Stderr, max(level, slog.LevelWarn)),
+	)
+	return slog.New(handler), nil
 }
@@ -0,0 +1,57 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+type Finding struct {
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	EndLine   int       `json:"end_line"`         // differs from Line for multiline patterns
+	Column    int       `json:"column"`           // 1 based byte offset of the match within the line
+	EndColumn int       `json:"end_column"`       // column following the last byte of the match, on EndLine
+	Offset    *int64    `json:"offset,omitempty"` // byte offset of the match within the file, member or cell, nil when unknown such as for utf-16
+	Location  *Location `json:"location,omitempty"`
+	Pattern   string    `json:"pattern"`
+	Severity  Severity  `json:"severity"`
+	Match     string    `json:"match"`             // the secret, the capture group if the pattern has one
+	Context   string    `json:"context,omitempty"` // the full match, when it differs from the secret
+
+	// MatchSHA256 is the hex sha256 of the secret before redaction
+	MatchSHA256 string `json:"match_sha256"`
+
+	Fingerprint string `json:"fingerprint"`
+	Suppressed  bool   `json:"suppressed,omitempty"`
+
+	// Rejected is set for matches failing the validators of their pattern,
+	// which are only reported when testing patterns
+	Rejected bool `json:"rejected,omitempty"`
+
+	// set when scanning git history
+	Commit string `json:"commit,omitempty"`
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
+}
+
+// Location holds extractor specific coordinates of a finding
+type Location struct {
+	Archive string `json:"archive,omitempty"` // outermost archive containing the file
+	Member  string `json:"member,omitempty"`  // path within the archive, nested archives are joined with !
+	Sheet   string `json:"sheet,omitempty"`
+	Cell    string `json:"cell,omitempty"`    // cell reference including the sheet, e.g. Sheet2!C14
+	Segment string `json:"segment,omitempty"` // location reported by an extractor plugin, such as a key path
+}
+
+func (l Location) IsZero() bool {
+	return l == Location{}
+}
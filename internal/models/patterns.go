@@ -14,18 +14,55 @@
 
 package models
 
-import "regexp"
+import (
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"regexp"
+)
 
 type PatternDefinition struct {
-	Name      string
-	Regex     string
-	Severity  string
-	Validator string
+	Name     string
+	Regex    string
+	Severity Severity
+
+	// Validators must accept a match for it to be reported, or any one of
+	// them when ValidatorAny is set
+	Validators   []ValidatorSpec
+	ValidatorAny bool
+
+	// SecretGroup names or numbers the capture group holding the secret,
+	// a group named "secret" is used when empty
+	SecretGroup string
+
+	// Multiline patterns match across lines, against a sliding window of
+	// the content joined by newlines
+	Multiline bool
+
+	// ContextLines is the number of lines before and after a match passed
+	// to the validator
+	ContextLines int
 }
 
 type CompiledPattern struct {
-	Name      string
-	Severity  string
-	Regex     *regexp.Regexp
-	Validator string
+	Name         string
+	Severity     Severity
+	Regex        *regexp.Regexp
+	Validators   []ValidatorSpec
+	ValidatorAny bool
+
+	// Validator combines the resolved Validators, nil when there are none
+	Validator validators.Validator
+
+	// SecretGroup is the index of the capture group holding the secret, 0
+	// for the whole match
+	SecretGroup int
+
+	Multiline    bool
+	ContextLines int
+}
+
+// ValidatorSpec references a registered validator, or a validator factory
+// with its parameters
+type ValidatorSpec struct {
+	Name   string
+	Params map[string]any
 }
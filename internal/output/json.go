@@ -0,0 +1,38 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"io"
+)
+
+// JSONWriter streams findings as newline delimited json
+type JSONWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{encoder: json.NewEncoder(w)}
+}
+
+func (j *JSONWriter) Write(finding models.Finding) error {
+	return j.encoder.Encode(finding)
+}
+
+func (j *JSONWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"io"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+var Formats = []string{FormatJSON, FormatSARIF}
+
+// Writer renders findings in an output format. Write is not safe for
+// concurrent use, Close must be called once all findings are written.
+type Writer interface {
+	Write(finding models.Finding) error
+	Close() error
+}
+
+// New returns a Writer for the named format. patterns describes the rules
+// that were active during the scan for formats which report them.
+func New(format string, w io.Writer, patterns []models.CompiledPattern) (Writer, error) {
+	switch format {
+	case FormatJSON, "":
+		return NewJSONWriter(w), nil
+	case FormatSARIF:
+		return NewSARIFWriter(w, patterns), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected one of %v", format, Formats)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "secret-scan"
+)
+
+// SARIFWriter collects findings and writes a single SARIF 2.1.0 log on Close
+type SARIFWriter struct {
+	w         io.Writer
+	rules     []sarifRule
+	ruleIndex map[string]int
+	results   []sarifResult
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	Severity         string   `json:"severity,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int    `json:"startLine"`
+	EndLine     int    `json:"endLine,omitempty"`
+	StartColumn int    `json:"startColumn,omitempty"`
+	EndColumn   int    `json:"endColumn,omitempty"`
+	ByteOffset  *int64 `json:"byteOffset,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+func NewSARIFWriter(w io.Writer, patterns []models.CompiledPattern) *SARIFWriter {
+	s := &SARIFWriter{
+		w:         w,
+		ruleIndex: make(map[string]int),
+		results:   make([]sarifResult, 0),
+	}
+
+	for _, pattern := range patterns {
+		s.addRule(pattern.Name, pattern.Severity)
+	}
+
+	return s
+}
+
+func (s *SARIFWriter) addRule(name string, severity models.Severity) int {
+	if index, ok := s.ruleIndex[name]; ok {
+		return index
+	}
+
+	s.rules = append(s.rules, sarifRule{
+		ID:                   ruleID(name),
+		Name:                 name,
+		ShortDescription:     sarifMessage{Text: name},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(severity)},
+		Properties: sarifProperties{
+			SecuritySeverity: securitySeverity(severity),
+			Severity:         severity.String(),
+			Tags:             []string{"security", "secret"},
+		},
+	})
+
+	index := len(s.rules) - 1
+	s.ruleIndex[name] = index
+	return index
+}
+
+func (s *SARIFWriter) Write(finding models.Finding) error {
+	index := s.addRule(finding.Pattern, finding.Severity)
+
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: artifactURI(finding.File)},
+			Region: sarifRegion{
+				StartLine:   finding.Line,
+				EndLine:     finding.EndLine,
+				StartColumn: finding.Column,
+				EndColumn:   finding.EndColumn,
+			},
+		},
+	}
+
+	// a byte offset within a spreadsheet cell is not an offset in the file
+	if finding.Location != nil && finding.Location.Cell != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{
+			FullyQualifiedName: finding.Location.Cell,
+			Kind:               "element",
+		}}
+	} else {
+		location.PhysicalLocation.Region.ByteOffset = finding.Offset
+	}
+
+	result := sarifResult{
+		RuleID:    s.rules[index].ID,
+		RuleIndex: index,
+		Level:     sarifLevel(finding.Severity),
+		Message:   sarifMessage{Text: fmt.Sprintf("Possible %s found", finding.Pattern)},
+		Locations: []sarifLocation{location},
+	}
+
+	if finding.Fingerprint != "" {
+		result.PartialFingerprints = map[string]string{
+			"secretScanFingerprint/v1": finding.Fingerprint,
+			"matchSha256/v1":           finding.MatchSHA256,
+		}
+	}
+
+	if finding.Suppressed {
+		result.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+	}
+
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *SARIFWriter) Close() error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:  toolName,
+					Rules: s.rules,
+				},
+			},
+			Results: s.results,
+		}},
+	}
+
+	encoder := json.NewEncoder(s.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// ruleID converts a pattern name into a stable identifier, e.g.
+// "AWS Access Key ID" becomes "aws-access-key-id"
+func ruleID(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func sarifLevel(severity models.Severity) string {
+	switch severity {
+	case models.SeverityCritical, models.SeverityHigh:
+		return "error"
+	case models.SeverityLow:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// securitySeverity maps to the numeric scores used by code scanning
+// dashboards to bucket results
+func securitySeverity(severity models.Severity) string {
+	switch severity {
+	case models.SeverityCritical:
+		return "9.5"
+	case models.SeverityHigh:
+		return "8.0"
+	case models.SeverityMedium:
+		return "5.5"
+	case models.SeverityLow:
+		return "2.0"
+	default:
+		return ""
+	}
+}
+
+// artifactURI converts a file path to a SARIF uri reference
+func artifactURI(path string) string {
+	path = filepath.ToSlash(path)
+
+	// a windows path such as C:/src/app.env becomes file:///C:/src/app.env
+	if hasDriveLetter(path) {
+		return (&url.URL{Scheme: "file", Path: "/" + strings.ReplaceAll(path, "\\", "/")}).String()
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") {
+		return (&url.URL{Scheme: "file", Path: path}).String()
+	}
+	// escaped as well, a space or # is not valid in a uri reference
+	return (&url.URL{Path: strings.TrimPrefix(path, "./")}).String()
+}
+
+// hasDriveLetter reports whether path starts with a windows drive, whatever
+// the platform the scan runs on
+func hasDriveLetter(path string) bool {
+	if len(path) < 3 || path[1] != ':' || (path[2] != '/' && path[2] != '\\') {
+		return false
+	}
+	letter := path[0] | 0x20 // lower case
+	return letter >= 'a' && letter <= 'z'
+}
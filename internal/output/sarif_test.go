@@ -0,0 +1,38 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import "testing"
+
+func TestArtifactURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "src/app.env", want: "src/app.env"},
+		{path: "./src/app.env", want: "src/app.env"},
+		{path: "sp/my dir/a#1.env", want: "sp/my%20dir/a%231.env"},
+		{path: "config:prod.env", want: "./config:prod.env"},
+		{path: "/home/dev/my dir/a#1.env", want: "file:///home/dev/my%20dir/a%231.env"},
+		{path: "C:/src/app.env", want: "file:///C:/src/app.env"},
+		{path: `C:\src\my dir\app.env`, want: "file:///C:/src/my%20dir/app.env"},
+	}
+
+	for _, test := range tests {
+		if got := artifactURI(test.path); got != test.want {
+			t.Errorf("artifactURI(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// limits applied to every call of a Lua extractor
+const (
+	ExtractorTimeout   = 10 * time.Second
+	ExtractorMaxInput  = 16 * 1024 * 1024 // 16mb of file content
+	ExtractorMaxOutput = 64 * 1024 * 1024 // 64mb of returned text
+)
+
+// ExtractorLoader loads extractor plugins, each plugin file runs in its own
+// sandboxed VM which stays open while its extractors are in use
+type ExtractorLoader struct {
+	logger     *slog.Logger
+	vms        []*lua.LState
+	extractors []extractors.Extractor
+}
+
+func NewExtractorLoader(log *slog.Logger) *ExtractorLoader {
+	return &ExtractorLoader{logger: log}
+}
+
+func (el *ExtractorLoader) Close() {
+	for _, vm := range el.vms {
+		vm.Close()
+	}
+}
+
+// LoadExtractors runs every .lua file in dir and returns the extractors they
+// register with register_extractor
+func (el *ExtractorLoader) LoadExtractors(dir string) ([]extractors.Extractor, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".lua" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := el.loadFile(path); err != nil {
+			el.logger.Warn("failed to load extractor plugin", "path", path, "error", err)
+		}
+	}
+
+	el.logger.Debug("extractor plugins loaded", "count", len(el.extractors))
+	return el.extractors, nil
+}
+
+func (el *ExtractorLoader) loadFile(path string) error {
+	// the value and call stacks of a vm have a fixed size. the secrets in
+	// the content are not known yet, so printed text is logged as is.
+	vm := newLuaVM(el.logger, nil)
+	mutex := &sync.Mutex{}
+	registered := 0
+
+	vm.SetGlobal("register_extractor", vm.NewFunction(func(L *lua.LState) int {
+		extractor, err := parseExtractor(L.CheckTable(1))
+		if err != nil {
+			L.ArgError(1, err.Error())
+		}
+
+		extractor.vm = vm
+		extractor.mutex = mutex
+		el.extractors = append(el.extractors, extractor)
+		registered++
+
+		el.logger.Debug("registered extractor plugin", "extractor", extractor.name, "path", path)
+		return 0
+	}))
+
+	// loading is bounded like a call so a broken plugin cannot hang startup
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	release := watchMemory(cancel)
+	ctx, cancelTimeout := context.WithTimeout(ctx, ExtractorTimeout)
+	defer cancelTimeout()
+	vm.SetContext(ctx)
+	err := vm.DoFile(path)
+	vm.RemoveContext()
+	release()
+
+	if err != nil && errors.Is(context.Cause(ctx), errMemoryLimit) {
+		err = errMemoryLimit
+	}
+
+	if err != nil {
+		el.extractors = el.extractors[:len(el.extractors)-registered]
+		vm.Close()
+		return err
+	}
+
+	if registered == 0 {
+		el.logger.Warn("plugin registered no extractors", "path", path)
+		vm.Close()
+		return nil
+	}
+
+	el.vms = append(el.vms, vm)
+	return nil
+}
+
+// parseExtractor reads the table passed to register_extractor
+func parseExtractor(table *lua.LTable) (*LuaExtractor, error) {
+	extractor := &LuaExtractor{}
+
+	name, ok := table.RawGetString("name").(lua.LString)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("extractor requires a name")
+	}
+	extractor.name = string(name)
+
+	extract, ok := table.RawGetString("extract").(*lua.LFunction)
+	if !ok {
+		return nil, fmt.Errorf("extractor %s requires an extract function", extractor.name)
+	}
+	extractor.extract = extract
+
+	for _, extension := range stringList(table.RawGetString("extensions")) {
+		extractor.extensions = append(extractor.extensions, strings.ToLower(extension))
+	}
+	for _, magic := range stringList(table.RawGetString("magic")) {
+		extractor.magic = append(extractor.magic, []byte(magic))
+	}
+
+	if len(extractor.extensions) == 0 && len(extractor.magic) == 0 {
+		return nil, fmt.Errorf("extractor %s requires extensions or magic", extractor.name)
+	}
+
+	return extractor, nil
+}
+
+// stringList converts a Lua string or array of strings
+func stringList(value lua.LValue) []string {
+	switch v := value.(type) {
+	case lua.LString:
+		return []string{string(v)}
+	case *lua.LTable:
+		var values []string
+		v.ForEach(func(_, item lua.LValue) {
+			if s, ok := item.(lua.LString); ok {
+				values = append(values, string(s))
+			}
+		})
+		return values
+	}
+	return nil
+}
+
+// LuaExtractor is an extractor implemented by a plugin. The extract function
+// receives the file content as a string and returns an array of segments,
+// each either a string or a table with text, and optionally line and location.
+type LuaExtractor struct {
+	name       string
+	extensions []string
+	magic      [][]byte
+	extract    *lua.LFunction
+
+	vm    *lua.LState
+	mutex *sync.Mutex // guards vm, shared by the extractors of one plugin file
+}
+
+// Supports requires a declared extension and a declared magic prefix, for
+// those the plugin declares
+func (e *LuaExtractor) Supports(filename string, sample []byte) bool {
+	if len(e.extensions) > 0 {
+		lower := strings.ToLower(filename)
+		matched := false
+		for _, extension := range e.extensions {
+			if strings.HasSuffix(lower, extension) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(e.magic) > 0 {
+		for _, magic := range e.magic {
+			if extractors.HasMagic(sample, magic) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func (e *LuaExtractor) Extract(ctx context.Context, r io.Reader, emit func(line extractors.Line) error) error {
+	content, err := io.ReadAll(io.LimitReader(r, ExtractorMaxInput+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > ExtractorMaxInput {
+		return fmt.Errorf("extractor %s: content larger than %d bytes", e.name, ExtractorMaxInput)
+	}
+
+	segments, err := e.call(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	// line numbers continue from the previous segment unless given
+	number := 1
+	for index, segment := range segments {
+		if segment.line > 0 {
+			number = segment.line
+		} else if index > 0 {
+			number++
+		}
+
+		for i, text := range strings.Split(segment.text, "\n") {
+			if i > 0 {
+				number++
+			}
+			for _, window := range extractors.SplitLine(number, strings.TrimSuffix(text, "\r")) {
+				window.Location = models.Location{Segment: segment.location}
+				if err := emit(window); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+type segment struct {
+	text     string
+	line     int
+	location string
+}
+
+// call runs the extract function under the time limit and converts its result
+func (e *LuaExtractor) call(ctx context.Context, content []byte) ([]segment, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	defer watchMemory(cancel)()
+	ctx, cancelTimeout := context.WithTimeout(ctx, ExtractorTimeout)
+	defer cancelTimeout()
+	e.vm.SetContext(ctx)
+	defer e.vm.RemoveContext()
+
+	err := e.vm.CallByParam(lua.P{Fn: e.extract, NRet: 1, Protect: true}, lua.LString(content))
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errMemoryLimit) {
+			return nil, fmt.Errorf("extractor %s: %w", e.name, errMemoryLimit)
+		}
+		return nil, fmt.Errorf("extractor %s: %w", e.name, err)
+	}
+
+	result := e.vm.Get(-1)
+	e.vm.Pop(1)
+
+	table, ok := result.(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("extractor %s: extract must return a table, got %s", e.name, result.Type())
+	}
+
+	var segments []segment
+	size := 0
+	for index := 1; index <= table.Len(); index++ {
+		switch item := table.RawGetInt(index).(type) {
+		case lua.LString:
+			segments = append(segments, segment{text: string(item)})
+		case *lua.LTable:
+			next := segment{text: lua.LVAsString(item.RawGetString("text"))}
+			if line, ok := item.RawGetString("line").(lua.LNumber); ok {
+				next.line = int(line)
+			}
+			if location, ok := item.RawGetString("location").(lua.LString); ok {
+				next.location = string(location)
+			}
+			segments = append(segments, next)
+		default:
+			return nil, fmt.Errorf("extractor %s: segment %d is neither a string nor a table", e.name, index)
+		}
+
+		size += len(segments[len(segments)-1].text)
+		if size > ExtractorMaxOutput {
+			return nil, fmt.Errorf("extractor %s: more than %d bytes returned", e.name, ExtractorMaxOutput)
+		}
+	}
+
+	return segments, nil
+}
+
+var _ extractors.Extractor = (*LuaExtractor)(nil)
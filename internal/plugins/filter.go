@@ -0,0 +1,88 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"strings"
+)
+
+// PatternFilter limits which pattern definitions are compiled. The zero
+// value allows every pattern.
+type PatternFilter struct {
+	MinSeverity models.Severity   // patterns below this severity are dropped
+	Severities  []models.Severity // when set, only these severities are kept
+	Only        []string          // when set, only these pattern names are kept
+	Skip        []string          // pattern names to drop
+}
+
+// allows reports whether a pattern passes the filter and if not, why
+func (f PatternFilter) allows(pattern models.PatternDefinition) (bool, string) {
+	if pattern.Severity < f.MinSeverity {
+		return false, "below minimum severity"
+	}
+
+	if len(f.Severities) > 0 && !containsSeverity(f.Severities, pattern.Severity) {
+		return false, "severity not selected"
+	}
+
+	if len(f.Only) > 0 && !containsName(f.Only, pattern.Name) {
+		return false, "not in only-patterns"
+	}
+
+	if containsName(f.Skip, pattern.Name) {
+		return false, "in skip-patterns"
+	}
+
+	return true, ""
+}
+
+// unknownNames returns the names in Only and Skip matching no pattern
+func (f PatternFilter) unknownNames(patterns []models.PatternDefinition) []string {
+	var unknown []string
+
+	for _, name := range append(append([]string{}, f.Only...), f.Skip...) {
+		found := false
+		for _, pattern := range patterns {
+			if strings.EqualFold(pattern.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return unknown
+}
+
+func containsSeverity(severities []models.Severity, severity models.Severity) bool {
+	for _, next := range severities {
+		if next == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func containsName(names []string, name string) bool {
+	for _, next := range names {
+		if strings.EqualFold(next, name) {
+			return true
+		}
+	}
+	return false
+}
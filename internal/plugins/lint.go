@@ -0,0 +1,88 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"regexp"
+	"strings"
+)
+
+// LintIssue is a problem found in a pattern definition
+type LintIssue struct {
+	Pattern string // the pattern name, or the file for problems outside a pattern
+	Problem string
+}
+
+// Lint reports the entries LoadPatterns skipped and checks pattern
+// definitions for the mistakes CompilePatterns skips or rejects, and for
+// ones it accepts but which are unlikely to be intended
+func (pl *PatternLoader) Lint(patterns []models.PatternDefinition, filter PatternFilter) []LintIssue {
+	issues := append([]LintIssue{}, pl.skipped...)
+	report := func(pattern string, format string, args ...any) {
+		issues = append(issues, LintIssue{Pattern: pattern, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	registry := validators.NewRegistry()
+	pl.RegisterValidators(registry)
+
+	for _, name := range filter.unknownNames(patterns) {
+		report(name, "pattern filter references an unknown pattern")
+	}
+
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if ok, _ := filter.allows(pattern); !ok {
+			continue
+		}
+
+		lower := strings.ToLower(pattern.Name)
+		if seen[lower] {
+			report(pattern.Name, "duplicate pattern name, findings and suppressions cannot tell them apart")
+		}
+		seen[lower] = true
+
+		if _, err := resolveValidators(registry, pattern); err != nil {
+			report(pattern.Name, "%v", err)
+		}
+
+		if pattern.ContextLines > 0 && len(pattern.Validators) == 0 {
+			report(pattern.Name, "context_lines is only used by validators")
+		}
+
+		regex, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			report(pattern.Name, "invalid regex: %v", err)
+			continue
+		}
+
+		if _, err := secretGroupIndex(regex, pattern.SecretGroup); err != nil {
+			report(pattern.Name, "invalid secret group: %v", err)
+		}
+
+		if regex.MatchString("") {
+			report(pattern.Name, "regex matches empty text")
+		}
+
+		if pattern.Multiline && !strings.Contains(pattern.Regex, `\s`) && !strings.Contains(pattern.Regex, `\n`) &&
+			!strings.Contains(pattern.Regex, "(?s") {
+			report(pattern.Name, `multiline regex cannot match a line break, use [\s\S], \n or (?s)`)
+		}
+	}
+
+	return issues
+}
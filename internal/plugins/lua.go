@@ -16,31 +16,62 @@ package plugins
 
 import (
 	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"github.com/clarityoverclever/secret-scan/patterns"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
-	"secret-scan/internal/models"
-	"secret-scan/patterns"
+	"strconv"
+	"strings"
+	"sync"
 
 	lua "github.com/yuin/gopher-lua"
 )
 
+// MaxContextLines bounds the context_lines of a pattern, lines are held in
+// memory until the context after them has been read
+const MaxContextLines = 50
+
 type PatternLoader struct {
 	logger *slog.Logger
 	vm     *lua.LState
+	mutex  sync.Mutex // guards vm once validators are in use
+	redact redact.Mode
+	match  string // being validated, redacted from printed text
+
+	// luaValidators are registered by pattern files, by name
+	luaValidators map[string]*lua.LFunction
+	inlineCount   int // inline validators named so far
+
+	// skipped records the pattern entries which could not be loaded
+	skipped []LintIssue
 }
 
 func NewPatternLoader(log *slog.Logger) *PatternLoader {
-	return &PatternLoader{
-		logger: log,
-		vm:     newLuaVM(),
+	pl := &PatternLoader{
+		logger:        log,
+		luaValidators: make(map[string]*lua.LFunction),
 	}
+	pl.vm = newLuaVM(log, func(text string) string {
+		return pl.redact.Text(text, pl.match)
+	})
+
+	pl.vm.SetGlobal("register_validator", pl.vm.NewFunction(pl.registerValidator))
+	return pl
 }
 
-func newLuaVM() *lua.LState {
-	vm := lua.NewState()
+// newLuaVM creates a sandboxed VM, printed text is passed through redactText
+// unless it is nil
+func newLuaVM(log *slog.Logger, redactText func(string) string) *lua.LState {
+	vm := lua.NewState(lua.Options{
+		CallStackSize:   luaCallStackSize,
+		RegistrySize:    luaRegistrySize,
+		RegistryMaxSize: luaRegistryMaxSize,
+	})
 
 	// Security: Disable dangerous Lua functions
 	vm.SetGlobal("dofile", lua.LNil)
@@ -53,9 +84,31 @@ func newLuaVM() *lua.LState {
 	vm.SetGlobal("package", lua.LNil)
 	vm.SetGlobal("debug", lua.LNil)
 
+	limitBuiltins(vm)
+
+	// stdout carries the findings, printed text is a diagnostic
+	vm.SetGlobal("print", vm.NewFunction(luaPrint(log, redactText)))
+
 	return vm
 }
 
+// luaPrint replaces print, logging the printed values
+func luaPrint(log *slog.Logger, redactText func(string) string) lua.LGFunction {
+	return func(L *lua.LState) int {
+		values := make([]string, L.GetTop())
+		for index := range values {
+			values[index] = L.ToStringMeta(L.Get(index + 1)).String()
+		}
+
+		message := strings.Join(values, "\t")
+		if redactText != nil {
+			message = redactText(message)
+		}
+		log.Info("lua print", "message", message, "source", strings.TrimSuffix(L.Where(1), ":"))
+		return 0
+	}
+}
+
 func (pl *PatternLoader) Close() {
 	pl.vm.Close()
 }
@@ -177,12 +230,14 @@ func (pl *PatternLoader) extractPatterns(files []string, contents []string) ([]m
 		filename := files[index]
 
 		if err := pl.vm.DoString(content); err != nil {
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: filename, Problem: err.Error()})
 			pl.logger.Warn("failed to load pattern file", "path", filename, "error", err)
 			continue
 		}
 
 		patterns, err := pl.extractPatternsFromVM(filename)
 		if err != nil {
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: filename, Problem: err.Error()})
 			pl.logger.Warn("failed to extract patterns from VM", "path", filename, "error", err)
 			continue
 		}
@@ -218,6 +273,7 @@ func (pl *PatternLoader) extractPatternsFromVM(path string) ([]models.PatternDef
 		entry, ok := value.(*lua.LTable)
 		if !ok {
 			skippedCount++
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: path, Problem: "patterns entry is not a table"})
 			pl.logger.Warn("invalid table entry")
 			return // skip invalid table entries
 		}
@@ -225,10 +281,11 @@ func (pl *PatternLoader) extractPatternsFromVM(path string) ([]models.PatternDef
 		name := entry.RawGetString("name")
 		regex := entry.RawGetString("regex")
 		severity := entry.RawGetString("severity")
-		validator := entry.RawGetString("validator")
+		secretGroup := entry.RawGetString("secret_group")
 
 		if name == lua.LNil || regex == lua.LNil || severity == lua.LNil {
 			skippedCount++
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: lua.LVAsString(name), Problem: path + ": pattern requires name, regex and severity"})
 			pl.logger.Warn("skipping pattern with missing fields",
 				"has_name", name != lua.LNil,
 				"has_regex", regex != lua.LNil,
@@ -236,11 +293,37 @@ func (pl *PatternLoader) extractPatternsFromVM(path string) ([]models.PatternDef
 			return // skip invalid table entries
 		}
 
+		level, err := models.ParseSeverity(severity.String())
+		if err != nil {
+			skippedCount++
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: name.String(), Problem: err.Error()})
+			pl.logger.Warn("skipping pattern with invalid severity", "pattern", name.String(), "error", err)
+			return // skip invalid table entries
+		}
+
 		next := models.PatternDefinition{
-			Name:      name.String(),
-			Regex:     regex.String(),
-			Severity:  severity.String(),
-			Validator: validator.String(),
+			Name:     name.String(),
+			Regex:    regex.String(),
+			Severity: level,
+		}
+
+		if err := pl.parseValidators(entry, &next); err != nil {
+			skippedCount++
+			pl.skipped = append(pl.skipped, LintIssue{Pattern: next.Name, Problem: err.Error()})
+			pl.logger.Warn("skipping pattern with invalid validators", "pattern", next.Name, "error", err)
+			return // skip invalid table entries
+		}
+		if secretGroup != lua.LNil {
+			next.SecretGroup = secretGroup.String()
+		}
+		next.Multiline = lua.LVAsBool(entry.RawGetString("multiline"))
+
+		if contextLines, ok := entry.RawGetString("context_lines").(lua.LNumber); ok {
+			next.ContextLines = int(contextLines)
+			if next.ContextLines < 0 || next.ContextLines > MaxContextLines {
+				pl.logger.Warn("context_lines out of range, clamped", "pattern", next.Name, "context_lines", next.ContextLines)
+				next.ContextLines = min(max(next.ContextLines, 0), MaxContextLines)
+			}
 		}
 
 		patterns = append(patterns, next)
@@ -256,21 +339,76 @@ func (pl *PatternLoader) extractPatternsFromVM(path string) ([]models.PatternDef
 	return patterns, nil
 }
 
-func (pl *PatternLoader) CompilePatterns(patterns []models.PatternDefinition) ([]models.CompiledPattern, error) {
+// CompileOptions controls how the validators of patterns are resolved
+type CompileOptions struct {
+	// Validators resolves validator names, the built-in validators and those
+	// registered by the loaded pattern files are used when nil
+	Validators *validators.Registry
+
+	// Lenient disables patterns whose validators cannot be resolved with a
+	// warning, otherwise they fail compilation
+	Lenient bool
+
+	// Redact applies to matches appearing in the errors of Lua validators
+	Redact redact.Mode
+}
+
+func (pl *PatternLoader) CompilePatterns(patterns []models.PatternDefinition, filter PatternFilter, opts CompileOptions) ([]models.CompiledPattern, error) {
 	compiled := make([]models.CompiledPattern, 0, len(patterns))
 
+	pl.redact = opts.Redact
+
+	registry := opts.Validators
+	if registry == nil {
+		registry = validators.NewRegistry()
+		pl.RegisterValidators(registry)
+	}
+
+	for _, name := range filter.unknownNames(patterns) {
+		pl.logger.Warn("pattern filter references unknown pattern", "pattern", name)
+	}
+
 	for _, pattern := range patterns {
+		// filter before compiling so unwanted regexes cost nothing
+		if ok, reason := filter.allows(pattern); !ok {
+			pl.logger.Debug("pattern filtered", "pattern", pattern.Name, "reason", reason)
+			continue
+		}
+
 		regex, err := regexp.Compile(pattern.Regex)
 		if err != nil {
 			pl.logger.Warn("failed to compile regex", "pattern", pattern.Name, "error", err)
 			continue
 		}
 
+		group, err := secretGroupIndex(regex, pattern.SecretGroup)
+		if err != nil {
+			pl.logger.Warn("invalid secret group", "pattern", pattern.Name, "error", err)
+			continue
+		}
+
+		// a misspelt validator would otherwise report every match
+		validator, err := resolveValidators(registry, pattern)
+		if err != nil {
+			if !opts.Lenient {
+				return nil, fmt.Errorf("pattern %s: %w", pattern.Name, err)
+			}
+			pl.logger.Warn("pattern disabled, validators not resolved", "pattern", pattern.Name, "error", err)
+			continue
+		}
+
 		compiled = append(compiled, models.CompiledPattern{
-			Name:      pattern.Name,
-			Severity:  pattern.Severity,
-			Regex:     regex,
-			Validator: pattern.Validator,
+			Name:        pattern.Name,
+			Severity:    pattern.Severity,
+			Regex:       regex,
+			SecretGroup: group,
+			Multiline:   pattern.Multiline,
+
+			Validators:   pattern.Validators,
+			ValidatorAny: pattern.ValidatorAny,
+			Validator:    validator,
+
+			ContextLines: pattern.ContextLines,
 		})
 	}
 
@@ -282,3 +420,25 @@ func (pl *PatternLoader) CompilePatterns(patterns []models.PatternDefinition) ([
 
 	return compiled, nil
 }
+
+// secretGroupIndex resolves the capture group holding the secret from a
+// group number or name. Without one a group named "secret" is used if the
+// regex has it, otherwise the whole match.
+func secretGroupIndex(regex *regexp.Regexp, group string) (int, error) {
+	if group == "" {
+		return max(regex.SubexpIndex("secret"), 0), nil
+	}
+
+	if index, err := strconv.Atoi(group); err == nil {
+		if index < 0 || index > regex.NumSubexp() {
+			return 0, fmt.Errorf("regex has no capture group %d", index)
+		}
+		return index, nil
+	}
+
+	index := regex.SubexpIndex(group)
+	if index < 0 {
+		return 0, fmt.Errorf("regex has no capture group named %q", group)
+	}
+	return index, nil
+}
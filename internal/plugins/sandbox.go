@@ -0,0 +1,118 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"errors"
+	"runtime/metrics"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// limits of every Lua VM, running out of either fails the call
+const (
+	luaCallStackSize   = 200        // nested Lua and Go function calls
+	luaRegistrySize    = 1024 * 20  // initial value stack slots
+	luaRegistryMaxSize = 1024 * 256 // value stack slots the stack may grow to
+)
+
+// LuaMaxString is the largest string string.rep and table.concat may build,
+// the builtins able to allocate an arbitrary amount in a single call
+const LuaMaxString = 64 * 1024 * 1024 // 64mb
+
+// LuaMaxMemory bounds how far the heap may grow during a single call of a
+// Lua validator or extractor, the call fails once it grew further
+const LuaMaxMemory = 256 * 1024 * 1024 // 256mb
+
+// memoryInterval is how often the heap is sampled during a call
+const memoryInterval = time.Millisecond
+
+var errMemoryLimit = errors.New("memory limit exceeded")
+
+// heapMetric is the size of the heap objects, including unswept garbage
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// watchMemory calls stop with errMemoryLimit once the heap grew by more than
+// LuaMaxMemory. Lua strings are go strings, so a loop such as s = s .. s
+// cannot be bounded per allocation, the VM is instead stopped through its
+// context before its next instruction. The allocation in progress when the
+// limit is reached completes first, and the heap is that of the process, so
+// the limit leaves room for both. release stops watching.
+func watchMemory(stop func(error)) (release func()) {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	baseline := sample[0].Value.Uint64()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(memoryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				metrics.Read(sample)
+				if heap := sample[0].Value.Uint64(); heap > baseline && heap-baseline > LuaMaxMemory {
+					stop(errMemoryLimit)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// limitBuiltins replaces the builtins able to build huge strings in one
+// call, which the context of a call cannot interrupt, with size checked ones
+func limitBuiltins(vm *lua.LState) {
+	if stringLib, ok := vm.GetGlobal("string").(*lua.LTable); ok {
+		rep, _ := stringLib.RawGetString("rep").(*lua.LFunction)
+		if rep != nil {
+			stringLib.RawSetString("rep", vm.NewFunction(func(L *lua.LState) int {
+				size, count := len(L.CheckString(1)), L.CheckInt(2)
+				if size > 0 && count > LuaMaxString/size {
+					L.RaiseError("string.rep: result larger than %d bytes", LuaMaxString)
+				}
+				return rep.GFunction(L)
+			}))
+		}
+	}
+
+	if tableLib, ok := vm.GetGlobal("table").(*lua.LTable); ok {
+		concat, _ := tableLib.RawGetString("concat").(*lua.LFunction)
+		if concat != nil {
+			tableLib.RawSetString("concat", vm.NewFunction(func(L *lua.LState) int {
+				table := L.CheckTable(1)
+				separator := len(L.OptString(2, ""))
+				first, last := L.OptInt(3, 1), L.OptInt(4, table.Len())
+
+				size := 0
+				for index := max(first, 1); index <= min(last, table.Len()); index++ {
+					if value := table.RawGetInt(index); lua.LVCanConvToString(value) {
+						size += len(lua.LVAsString(value)) + separator
+					}
+					if size > LuaMaxString {
+						L.RaiseError("table.concat: result larger than %d bytes", LuaMaxString)
+					}
+				}
+				return concat.GFunction(L)
+			}))
+		}
+	}
+}
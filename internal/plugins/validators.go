@@ -0,0 +1,287 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"strconv"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ValidatorInstructionLimit bounds the VM instructions a single call of a
+// Lua validator may execute
+const ValidatorInstructionLimit = 1_000_000
+
+var errInstructionLimit = errors.New("instruction limit exceeded")
+
+// inlineValidatorPrefix names validators defined inline in a pattern
+const inlineValidatorPrefix = "lua:"
+
+// parseValidators reads the validator and validators fields of a pattern.
+// Each validator is the name of a registered one, a table of a name and its
+// parameters such as {"entropy", min = 4.2}, or an inline function.
+// validator_mode = "any" accepts a match when any validator does.
+func (pl *PatternLoader) parseValidators(entry *lua.LTable, pattern *models.PatternDefinition) error {
+	var values []lua.LValue
+	if validator := entry.RawGetString("validator"); validator != lua.LNil {
+		values = append(values, validator)
+	}
+	switch list := entry.RawGetString("validators").(type) {
+	case *lua.LTable:
+		for index := 1; index <= list.Len(); index++ {
+			values = append(values, list.RawGetInt(index))
+		}
+	case *lua.LNilType:
+	default:
+		return fmt.Errorf("validators must be a table")
+	}
+
+	for index, value := range values {
+		switch v := value.(type) {
+		case lua.LString:
+			pattern.Validators = append(pattern.Validators, models.ValidatorSpec{Name: string(v)})
+		case *lua.LFunction:
+			// numbered across all pattern files, patterns of the same name
+			// in different files keep their own validators
+			pl.inlineCount++
+			name := inlineValidatorPrefix + pattern.Name + "#" + strconv.Itoa(pl.inlineCount)
+			pl.luaValidators[name] = v
+			pattern.Validators = append(pattern.Validators, models.ValidatorSpec{Name: name})
+		case *lua.LTable:
+			spec, err := validatorSpec(v)
+			if err != nil {
+				return err
+			}
+			pattern.Validators = append(pattern.Validators, spec)
+		default:
+			return fmt.Errorf("validator %d is neither a name, a table nor a function", index+1)
+		}
+	}
+
+	switch mode := lua.LVAsString(entry.RawGetString("validator_mode")); mode {
+	case "", "all":
+	case "any":
+		pattern.ValidatorAny = true
+	default:
+		return fmt.Errorf("validator_mode must be all or any, got %q", mode)
+	}
+
+	return nil
+}
+
+// resolveValidators combines the validators of a pattern, nil when it has none
+func resolveValidators(registry *validators.Registry, pattern models.PatternDefinition) (validators.Validator, error) {
+	if len(pattern.Validators) == 0 {
+		return nil, nil
+	}
+
+	resolved := make([]validators.Validator, 0, len(pattern.Validators))
+	for _, spec := range pattern.Validators {
+		validator, err := registry.Resolve(spec.Name, spec.Params)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, validator)
+	}
+
+	if pattern.ValidatorAny {
+		return validators.Any(resolved...), nil
+	}
+	return validators.All(resolved...), nil
+}
+
+// validatorSpec converts {"name", key = value, ...}, parameter values are
+// numbers, strings, booleans or lists of them
+func validatorSpec(table *lua.LTable) (models.ValidatorSpec, error) {
+	name, ok := table.RawGetInt(1).(lua.LString)
+	if !ok || name == "" {
+		return models.ValidatorSpec{}, fmt.Errorf("validator table requires a name as its first element")
+	}
+
+	spec := models.ValidatorSpec{Name: string(name), Params: make(map[string]any)}
+	var err error
+	table.ForEach(func(key, value lua.LValue) {
+		k, ok := key.(lua.LString)
+		if !ok || err != nil {
+			return
+		}
+		if spec.Params[string(k)], err = luaParam(value); err != nil {
+			err = fmt.Errorf("validator %s parameter %s: %w", spec.Name, k, err)
+		}
+	})
+
+	return spec, err
+}
+
+func luaParam(value lua.LValue) (any, error) {
+	switch v := value.(type) {
+	case lua.LNumber:
+		return float64(v), nil
+	case lua.LString:
+		return string(v), nil
+	case lua.LBool:
+		return bool(v), nil
+	case *lua.LTable:
+		list := make([]any, 0, v.Len())
+		for index := 1; index <= v.Len(); index++ {
+			item, err := luaParam(v.RawGetInt(index))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", value.Type())
+	}
+}
+
+// registerValidator implements register_validator(name, function(match, line))
+// for pattern files
+func (pl *PatternLoader) registerValidator(L *lua.LState) int {
+	name := L.CheckString(1)
+	fn := L.CheckFunction(2)
+
+	if name == "" {
+		L.ArgError(1, "validator name must not be empty")
+	}
+
+	pl.luaValidators[name] = fn
+	pl.logger.Debug("registered lua validator", "validator", name)
+	return 0
+}
+
+// RegisterValidators adds the validators defined by the loaded pattern files
+// to the registry. They run on the loader VM, which must stay open while
+// they are in use.
+func (pl *PatternLoader) RegisterValidators(registry *validators.Registry) {
+	for name, fn := range pl.luaValidators {
+		if registry.Has(name) {
+			pl.logger.Warn("lua validator replaces built-in validator", "validator", name)
+		}
+		registry.Register(name, &luaValidator{loader: pl, name: name, fn: fn})
+	}
+}
+
+// luaValidator calls a Lua function with the match, the line and a context
+// table holding line, before, after, path and file_type. The result is
+// converted to a boolean as Lua would.
+type luaValidator struct {
+	loader *PatternLoader
+	name   string
+	fn     *lua.LFunction
+}
+
+func (v *luaValidator) Validate(match string, context validators.Context) bool {
+	ok, err := v.loader.callValidator(v.fn, match, context)
+	if err != nil {
+		// report rather than hide a possible secret when the validator is broken
+		v.loader.logger.Warn("lua validator failed", "validator", v.name, "error", v.loader.redact.Text(err.Error(), match))
+		return true
+	}
+	return ok
+}
+
+// callValidator runs fn under the instruction limit. Lua states are not safe
+// for concurrent use, so calls from scan workers are serialized.
+func (pl *PatternLoader) callValidator(fn *lua.LFunction, match string, context validators.Context) (bool, error) {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+
+	pl.match = match
+	defer func() { pl.match = "" }()
+
+	limit := newInstructionLimit(ValidatorInstructionLimit)
+	defer watchMemory(limit.stop)()
+	pl.vm.SetContext(limit)
+	defer pl.vm.RemoveContext()
+
+	table := pl.vm.NewTable()
+	table.RawSetString("line", lua.LString(context.Line))
+	table.RawSetString("before", stringTable(pl.vm, context.Before))
+	table.RawSetString("after", stringTable(pl.vm, context.After))
+	table.RawSetString("path", lua.LString(context.Path))
+	table.RawSetString("file_type", lua.LString(context.FileType))
+
+	err := pl.vm.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, lua.LString(match), lua.LString(context.Line), table)
+	if err != nil {
+		if limitErr := limit.Err(); limitErr != nil {
+			return false, limitErr
+		}
+		return false, err
+	}
+
+	result := pl.vm.Get(-1)
+	pl.vm.Pop(1)
+	return lua.LVAsBool(result), nil
+}
+
+func stringTable(L *lua.LState, values []string) *lua.LTable {
+	table := L.CreateTable(len(values), 0)
+	for _, value := range values {
+		table.Append(lua.LString(value))
+	}
+	return table
+}
+
+// instructionLimit is a context cancelled after a number of Done calls. The
+// VM checks Done of its context once per instruction, which makes this an
+// instruction count rather than a wall clock limit. stop cancels it early.
+type instructionLimit struct {
+	remaining int
+	done      chan struct{}
+	once      sync.Once
+	err       error // set before done is closed
+}
+
+func newInstructionLimit(instructions int) *instructionLimit {
+	return &instructionLimit{remaining: instructions, done: make(chan struct{})}
+}
+
+func (l *instructionLimit) Done() <-chan struct{} {
+	l.remaining--
+	if l.remaining < 0 {
+		l.stop(errInstructionLimit)
+	}
+	return l.done
+}
+
+func (l *instructionLimit) stop(err error) {
+	l.once.Do(func() {
+		l.err = err
+		close(l.done)
+	})
+}
+
+func (l *instructionLimit) Err() error {
+	select {
+	case <-l.done:
+		return l.err
+	default:
+		return nil
+	}
+}
+
+func (l *instructionLimit) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (l *instructionLimit) Value(key any) any           { return nil }
+
+var _ context.Context = (*instructionLimit)(nil)
@@ -0,0 +1,89 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Mode selects how much of a secret is written to output and logs
+type Mode string
+
+const (
+	None    Mode = "none"    // secrets are written as found
+	Partial Mode = "partial" // the ends of a secret are kept, e.g. AKIA************XYZ1
+	Full    Mode = "full"    // secrets are replaced by Redacted
+)
+
+var Modes = []Mode{None, Partial, Full}
+
+// Redacted replaces secrets in Full mode
+const Redacted = "[REDACTED]"
+
+// ParseMode converts a mode name, an empty name is None
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case None, Partial, Full:
+		return mode, nil
+	case "":
+		return None, nil
+	default:
+		return None, fmt.Errorf("unknown redaction mode %q, expected one of %v", name, Modes)
+	}
+}
+
+// Secret applies the mode to a secret
+func (m Mode) Secret(secret string) string {
+	switch m {
+	case Full:
+		return Redacted
+	case Partial:
+		return partial(secret)
+	default:
+		return secret
+	}
+}
+
+// Text applies the mode to every occurrence of secret within text, such as
+// the full match around a capture group or an error message
+func (m Mode) Text(text string, secret string) string {
+	if m == None || m == "" || secret == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, secret, m.Secret(secret))
+}
+
+// partial keeps up to four characters at each end of a secret, a quarter of
+// its length on each side for short secrets, and masks the rest
+func partial(secret string) string {
+	runes := []rune(secret)
+	keep := min(len(runes)/4, 4)
+
+	var b strings.Builder
+	b.WriteString(string(runes[:keep]))
+	b.WriteString(strings.Repeat("*", len(runes)-2*keep))
+	b.WriteString(string(runes[len(runes)-keep:]))
+	return b.String()
+}
+
+// Hash returns the hex sha256 of a secret, which identifies it across runs
+// without revealing it
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
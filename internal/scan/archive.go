@@ -0,0 +1,101 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"io"
+	"path"
+)
+
+// memberSeparator joins an archive path and a member path, for example
+// release.zip!config/app.yaml
+const memberSeparator = "!"
+
+// scanContainer scans each member of an archive with the normal extractor
+// registry, descending into nested archives up to the configured depth
+func (s *Scanner) scanContainer(ctx context.Context, container extractors.Container, r io.Reader, src source, budget *extractors.Budget, depth int) error {
+	s.logger.Debug("scanning archive", "path", src.path, "depth", depth)
+
+	// findings name the outermost archive and the path within it
+	if src.location.Archive == "" {
+		src.location.Archive = src.path
+	}
+
+	err := container.Members(ctx, r, budget, func(member extractors.Member) error {
+		memberSrc := src
+		name := member.Name
+
+		if member.Transparent {
+			if name == "" {
+				name = extractors.DecompressedName(path.Base(src.path))
+			}
+		} else {
+			memberSrc.path = src.path + memberSeparator + member.Name
+			memberSrc.relPath = src.relPath + memberSeparator + member.Name
+			if src.location.Member != "" {
+				memberSrc.location.Member = src.location.Member + memberSeparator + member.Name
+			} else {
+				memberSrc.location.Member = member.Name
+			}
+		}
+
+		// the remaining members would be cut off at once
+		if err := budget.Err(); err != nil {
+			return err
+		}
+
+		if member.Skipped != nil {
+			s.logger.Warn("skipping archive member", "path", memberSrc.path, "error", member.Skipped)
+			return nil
+		}
+
+		buffered := bufio.NewReaderSize(member.Reader, extractors.SampleSize)
+		sample, err := buffered.Peek(extractors.SampleSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+
+		if nested := s.registry.MatchContainer(name, sample); nested != nil {
+			if depth >= budget.Limits().MaxDepth {
+				s.logger.Warn("skipping nested archive beyond maximum depth", "path", memberSrc.path, "depth", depth)
+				return nil
+			}
+			return s.scanContainer(ctx, nested, buffered, memberSrc, budget, depth+1)
+		}
+
+		extractor := s.registry.Match(name, sample)
+		if extractor == nil {
+			s.logger.Debug("skipping unsupported archive member", "path", memberSrc.path)
+			return nil
+		}
+
+		return s.scanContent(ctx, extractor, budget.Charge(buffered), memberSrc)
+	})
+
+	// a limit aborts the whole archive, members scanned so far are kept
+	if errors.Is(err, extractors.ErrArchiveLimit) {
+		s.logger.Warn("archive scan aborted", "path", src.path, "error", err)
+		return nil
+	}
+	if err != nil && ctx.Err() == nil {
+		s.logger.Warn("failed to read archive", "path", src.path, "error", err)
+		return nil
+	}
+	return err
+}
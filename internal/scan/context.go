@@ -0,0 +1,120 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"path"
+	"strings"
+)
+
+// maxPendingWindows bounds how many windows of a long line wait for the
+// context after them, beyond that they are scanned with what is available
+const maxPendingWindows = 16
+
+// lineQueue delays scanning each line until the lines of context after it
+// have been extracted, keeping the lines of context before it
+type lineQueue struct {
+	context int
+	lines   []extractors.Line
+	next    int // index of the next line to scan
+}
+
+// push adds an extracted line and scans the lines whose context is complete
+func (q *lineQueue) push(line extractors.Line, scan func(index int)) {
+	q.lines = append(q.lines, line)
+
+	last := line.Number
+	for q.next < len(q.lines) {
+		pending := len(q.lines) - q.next
+		if last-q.lines[q.next].Number < q.context && pending <= q.context+maxPendingWindows {
+			break
+		}
+		scan(q.next)
+		q.next++
+	}
+
+	// keep the lines of context before the next line
+	if drop := q.next - q.context; drop > 0 {
+		q.lines = append(q.lines[:0], q.lines[drop:]...)
+		q.next -= drop
+	}
+}
+
+// flush scans the remaining lines at the end of the content
+func (q *lineQueue) flush(scan func(index int)) {
+	for ; q.next < len(q.lines); q.next++ {
+		scan(q.next)
+	}
+}
+
+// surrounding returns the lines around the line at index
+func (q *lineQueue) surrounding(index int) surroundings {
+	return surroundings{before: q.lines[:index], after: q.lines[index+1:]}
+}
+
+// surroundings are the lines extracted around a match
+type surroundings struct {
+	before []extractors.Line
+	after  []extractors.Line
+}
+
+// context builds the validator context of a match on lines first to last,
+// with up to n contiguous lines on each side
+func (s surroundings) context(src source, line string, first int, last int, n int) validators.Context {
+	context := validators.Context{
+		Line:     line,
+		Path:     src.path,
+		FileType: fileType(src.path),
+	}
+
+	expected := first
+	for index := len(s.before) - 1; index >= 0 && len(context.Before) < n; index-- {
+		number := s.before[index].Number
+		if number >= first {
+			continue // another window of the same line
+		}
+		if number < expected-1 {
+			break // a gap, such as between diff hunks
+		}
+		context.Before = append([]string{s.before[index].Text}, context.Before...)
+		expected = number
+	}
+
+	expected = last
+	for index := 0; index < len(s.after) && len(context.After) < n; index++ {
+		number := s.after[index].Number
+		if number <= last {
+			continue
+		}
+		if number > expected+1 {
+			break
+		}
+		context.After = append(context.After, s.after[index].Text)
+		expected = number
+	}
+
+	return context
+}
+
+// fileType returns the lower case extension of a path, or of the archive
+// member it names
+func fileType(name string) string {
+	if index := strings.LastIndex(name, memberSeparator); index >= 0 {
+		name = name[index+1:]
+	}
+	return strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// commitMarker starts the commit header lines produced by commitFormat.
+// Diff content lines always start with a prefix character so can never
+// begin with a NUL byte.
+const commitMarker = "\x00"
+
+// commitFormat is passed to git log --format, fields are NUL separated
+const commitFormat = "--format=" + "%x00%H%x00%an <%ae>%x00%aI"
+
+type commitInfo struct {
+	sha    string
+	author string
+	date   string
+}
+
+type diffLine struct {
+	number int // line number in the new version of the file
+	text   string
+	added  bool // false for context lines
+}
+
+// diffFile holds the hunks of one file in one commit, or in the index
+type diffFile struct {
+	commit commitInfo
+	path   string
+	lines  []diffLine
+}
+
+// parseDiff reads unified diff output of git log -p or git diff and calls fn
+// once per file with at least one added line. Binary and deleted files are
+// skipped.
+func parseDiff(r io.Reader, fn func(file diffFile) error) error {
+	reader := bufio.NewReader(r)
+
+	var commit commitInfo
+	var current *diffFile
+	var oldLeft, newLeft, lineNum int
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		file := *current
+		current = nil
+
+		for _, line := range file.lines {
+			if line.added {
+				return fn(file)
+			}
+		}
+		return nil
+	}
+
+	for {
+		raw, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if raw == "" && readErr == io.EOF {
+			break
+		}
+		line := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+
+		// inside a hunk the remaining counts decide what a line is, so added
+		// content such as "++ x" is never mistaken for a file header
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if current != nil {
+					current.lines = append(current.lines, diffLine{number: lineNum, text: line[1:], added: true})
+				}
+				lineNum++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				if current != nil {
+					text := ""
+					if len(line) > 0 {
+						text = line[1:]
+					}
+					current.lines = append(current.lines, diffLine{number: lineNum, text: text})
+				}
+				lineNum++
+				oldLeft--
+				newLeft--
+			}
+		} else {
+			switch {
+			case strings.HasPrefix(line, commitMarker):
+				if err := flush(); err != nil {
+					return err
+				}
+				commit = parseCommitHeader(line)
+			case strings.HasPrefix(line, "diff --git "):
+				if err := flush(); err != nil {
+					return err
+				}
+			case strings.HasPrefix(line, "+++ "):
+				path, ok := diffPath(line[len("+++ "):])
+				if ok {
+					current = &diffFile{commit: commit, path: path}
+				}
+			case strings.HasPrefix(line, "@@ "):
+				var err error
+				oldLeft, newLeft, lineNum, err = parseHunkHeader(line)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	return flush()
+}
+
+func parseCommitHeader(line string) commitInfo {
+	fields := strings.Split(strings.TrimPrefix(line, commitMarker), commitMarker)
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	return commitInfo{sha: fields[0], author: fields[1], date: fields[2]}
+}
+
+// diffPath strips the b/ prefix of a new file name, /dev/null means the
+// file was deleted
+func diffPath(name string) (string, bool) {
+	name = strings.TrimSuffix(name, "\t")
+	if strings.HasPrefix(name, "\"") {
+		unquoted, err := strconv.Unquote(name)
+		if err != nil {
+			return "", false
+		}
+		name = unquoted
+	}
+
+	if name == "/dev/null" {
+		return "", false
+	}
+	return strings.TrimPrefix(name, "b/"), true
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@" into the old and new line counts
+// and the first line number of the new file
+func parseHunkHeader(line string) (int, int, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", line)
+	}
+
+	_, oldCount, err := parseRange(strings.TrimPrefix(fields[1], "-"))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	newStart, newCount, err := parseRange(strings.TrimPrefix(fields[2], "+"))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+
+	return oldCount, newCount, newStart, nil
+}
+
+// parseRange parses "start,count", count defaults to 1 when omitted
+func parseRange(value string) (int, int, error) {
+	start, count, found := strings.Cut(value, ",")
+
+	startNum, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return startNum, 1, nil
+	}
+
+	countNum, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, err
+	}
+	return startNum, countNum, nil
+}
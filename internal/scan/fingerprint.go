@@ -0,0 +1,37 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+// Fingerprint identifies a finding independently of its line number so that
+// baselines survive unrelated edits to a file. path should be relative to the
+// scan root so the fingerprint is stable across checkouts.
+func Fingerprint(path string, pattern string, match string) string {
+	matchHash := sha256.Sum256([]byte(match))
+
+	h := sha256.New()
+	h.Write([]byte(filepath.ToSlash(path)))
+	h.Write([]byte{0})
+	h.Write([]byte(pattern))
+	h.Write([]byte{0})
+	h.Write(matchHash[:])
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitOptions selects the commits scanned by ScanGitHistory
+type GitOptions struct {
+	Range string // a revision range such as main..HEAD, all refs when empty
+	Since string // only commits more recent than this date, as accepted by git log --since
+}
+
+// ScanGitHistory scans the lines added by every selected commit of a local
+// repository, bare or not. Findings carry the commit, author and date, and
+// the path of the file at that commit.
+func (s *Scanner) ScanGitHistory(ctx context.Context, repo string, opts GitOptions) error {
+	args := []string{
+		"log", "-p", "-M",
+		// at least one unchanged line so inline suppressions on the preceding
+		// line are seen, more when validators look at surrounding lines
+		s.unifiedFlag(),
+		"--no-color", "--no-ext-diff", "--no-textconv",
+		commitFormat,
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Range != "" {
+		args = append(args, opts.Range)
+	} else {
+		args = append(args, "--all")
+	}
+	args = append(args, "--")
+
+	return s.scanGitDiff(ctx, repo, args)
+}
+
+// scanGitDiff runs a git command producing unified diff output and scans the
+// added lines of every file on the worker pool
+func (s *Scanner) scanGitDiff(ctx context.Context, repo string, args []string) error {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return fmt.Errorf("git executable not found: %w", err)
+	}
+
+	// core.quotePath=false keeps non-ascii paths readable
+	cmd := exec.CommandContext(ctx, gitPath, append([]string{"-C", repo, "-c", "core.quotePath=false"}, args...)...)
+	s.logger.Debug("running git", "args", cmd.Args)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start git: %w", err)
+	}
+
+	jobs := make(chan scanJob, 100)
+	var wg sync.WaitGroup
+
+	s.logger.Debug("starting worker pool", "workers", s.numWorkers)
+	for i := 0; i < s.numWorkers; i++ {
+		wg.Add(1)
+		go s.worker(ctx, i, jobs, &wg)
+	}
+
+	// diff paths are relative to the top of the worktree, where the ignore
+	// files are read from as scan does
+	root, err := worktree(ctx, gitPath, repo)
+	if err != nil {
+		s.logger.Debug("no worktree, ignore files are not applied", "repo", repo, "error", err)
+	}
+	ignored := newIgnoreMatcher(root, s.excludes, s.includes)
+
+	parseErr := parseDiff(stdout, func(file diffFile) error {
+		skip, rule := ignored.match(file.path, false)
+		if root != "" {
+			var err error
+			skip, rule, err = ignored.matchTree(filepath.Join(root, filepath.FromSlash(file.path)))
+			if err != nil {
+				s.logger.Warn("failed to load ignore files", "path", file.path, "error", err)
+			}
+		}
+		if skip {
+			s.logger.Debug("skipping ignored path", "path", file.path, "commit", file.commit.sha, "rule", rule)
+			return nil
+		}
+
+		select {
+		case jobs <- scanJob{path: file.path, relPath: file.path, diff: &file}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	close(jobs)
+	wg.Wait()
+
+	if parseErr != nil {
+		// stop git rather than block on a full pipe
+		_ = cmd.Process.Kill()
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+
+	if err := cmd.Wait(); err != nil && parseErr == nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if parseErr != nil {
+		return fmt.Errorf("failed to parse git output: %w", parseErr)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.writeErr
+}
+
+// worktree returns the top level directory of the worktree of repo, a bare
+// repository has none
+func worktree(ctx context.Context, gitPath string, repo string) (string, error) {
+	output, err := exec.CommandContext(ctx, gitPath, "-C", repo, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// scanDiff scans the added lines of a single file diff
+func (s *Scanner) scanDiff(job scanJob) error {
+	file := job.diff
+	s.logger.Debug("scanning diff", "path", file.path, "commit", file.commit.sha)
+
+	src := source{path: file.path, relPath: job.relPath}
+	multiline := s.newMultilineWindow(src)
+
+	// the unchanged and added lines of the diff are context for validators
+	lines := make([]extractors.Line, len(file.lines))
+	for index, line := range file.lines {
+		lines[index] = extractors.Line{Number: line.number, Text: line.text, Start: -1}
+	}
+
+	var findings []models.Finding
+	for index, line := range file.lines {
+		if !line.added {
+			continue
+		}
+
+		previous := ""
+		if index > 0 && file.lines[index-1].number == line.number-1 {
+			previous = file.lines[index-1].text
+		}
+
+		for _, window := range extractors.SplitLine(line.number, line.text) {
+			around := surroundings{before: lines[:index], after: lines[index+1:]}
+			findings = append(findings, s.scanLine(src, window, previous, around)...)
+			findings = append(findings, multiline.add(window)...)
+		}
+	}
+	findings = append(findings, multiline.flush()...)
+
+	for index := range findings {
+		findings[index].Commit = file.commit.sha
+		findings[index].Author = file.commit.author
+		findings[index].Date = file.commit.date
+	}
+
+	return s.emit(findings)
+}
+
+// unifiedFlag returns the number of unchanged lines git includes around each
+// change
+func (s *Scanner) unifiedFlag() string {
+	return fmt.Sprintf("--unified=%d", max(1, s.contextLines))
+}
+
+// ScanStaged scans the lines added or modified in the index of a repository,
+// as they will be committed. Line numbers are relative to the staged file.
+func (s *Scanner) ScanStaged(ctx context.Context, repo string) error {
+	args := []string{
+		"diff", "--cached", "-M",
+		s.unifiedFlag(),
+		"--diff-filter=ACMR",
+		"--no-color", "--no-ext-diff", "--no-textconv",
+		"--",
+	}
+
+	return s.scanGitDiff(ctx, repo, args)
+}
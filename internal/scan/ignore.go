@@ -15,5 +15,202 @@
 package scan
 
 import (
-	_ "github.com/sabhiram/go-gitignore"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
 )
+
+// IgnoreFileName is the dedicated ignore file honored alongside .gitignore
+const IgnoreFileName = ".secretscanignore"
+
+var ignoreFileNames = []string{".gitignore", IgnoreFileName}
+
+// alwaysSkipped directories are never descended regardless of ignore rules
+var alwaysSkipped = []string{".git", ".hg", ".svn"}
+
+// ignoreFile is a compiled ignore file scoped to the directory containing it
+type ignoreFile struct {
+	source    string
+	matcher   *ignore.GitIgnore
+	negations *ignore.GitIgnore
+}
+
+// ignoreMatcher applies hierarchical ignore files and -exclude/-include
+// globs to paths found while walking a directory tree. Ignore files are
+// loaded as their directory is entered, so it must be used from the walking
+// goroutine only.
+type ignoreMatcher struct {
+	root     string
+	dirs     map[string][]ignoreFile
+	loaded   map[string]bool // directories whose ignore files were read, see matchTree
+	excludes *ignore.GitIgnore
+	includes *ignore.GitIgnore
+}
+
+func newIgnoreMatcher(root string, excludes, includes []string) *ignoreMatcher {
+	m := &ignoreMatcher{
+		root:   filepath.Clean(root),
+		dirs:   make(map[string][]ignoreFile),
+		loaded: make(map[string]bool),
+	}
+
+	if len(excludes) > 0 {
+		m.excludes = ignore.CompileIgnoreLines(excludes...)
+	}
+	if len(includes) > 0 {
+		m.includes = ignore.CompileIgnoreLines(includes...)
+	}
+
+	return m
+}
+
+// load compiles the ignore files found in dir
+func (m *ignoreMatcher) load(dir string) error {
+	dir = filepath.Clean(dir)
+
+	for _, name := range ignoreFileNames {
+		path := filepath.Join(dir, name)
+
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read ignore file %s: %w", path, err)
+		}
+
+		lines := strings.Split(string(content), "\n")
+
+		// go-gitignore only applies negations within a single file, keep them
+		// separately so a deeper file can re-include what a parent ignored
+		var negations []string
+		for _, line := range lines {
+			if strings.HasPrefix(line, "!") {
+				negations = append(negations, line[1:])
+			}
+		}
+
+		m.dirs[dir] = append(m.dirs[dir], ignoreFile{
+			source:    path,
+			matcher:   ignore.CompileIgnoreLines(lines...),
+			negations: ignore.CompileIgnoreLines(negations...),
+		})
+	}
+
+	return nil
+}
+
+// match reports whether path should be skipped and the rule responsible
+func (m *ignoreMatcher) match(path string, isDir bool) (bool, string) {
+	path = filepath.Clean(path)
+	if path == m.root {
+		return false, ""
+	}
+
+	if isDir {
+		for _, name := range alwaysSkipped {
+			if filepath.Base(path) == name {
+				return true, "builtin:" + name
+			}
+		}
+	}
+
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil {
+		return false, ""
+	}
+	rel = filepath.ToSlash(rel)
+
+	ignored, rule := m.matchIgnoreFiles(path, isDir)
+
+	if m.excludes != nil {
+		if matched, pattern := m.excludes.MatchesPathHow(withDirSuffix(rel, isDir)); matched {
+			ignored, rule = true, "-exclude:"+pattern.Line
+		}
+	}
+
+	if !ignored && !isDir && m.includes != nil && !m.includes.MatchesPath(rel) {
+		ignored, rule = true, "-include: no pattern matched"
+	}
+
+	return ignored, rule
+}
+
+// matchTree is match for paths not found by walking the tree, such as the
+// files of a git diff. The ignore files of the directories leading to path
+// are loaded on first use and the directories themselves are matched, as a
+// walk would not have entered an ignored directory.
+func (m *ignoreMatcher) matchTree(path string) (bool, string, error) {
+	path = filepath.Clean(path)
+
+	for _, dir := range m.ancestors(path) {
+		if dir != m.root {
+			if skip, rule := m.match(dir, true); skip {
+				return true, rule, nil
+			}
+		}
+		if !m.loaded[dir] {
+			m.loaded[dir] = true
+			if err := m.load(dir); err != nil {
+				return false, "", err
+			}
+		}
+	}
+
+	skip, rule := m.match(path, false)
+	return skip, rule, nil
+}
+
+// matchIgnoreFiles evaluates ignore files from the scan root down to the
+// parent of path, deeper files taking precedence as they do in git
+func (m *ignoreMatcher) matchIgnoreFiles(path string, isDir bool) (bool, string) {
+	var ignored bool
+	var rule string
+
+	for _, dir := range m.ancestors(path) {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			continue
+		}
+		rel = withDirSuffix(filepath.ToSlash(rel), isDir)
+
+		for _, file := range m.dirs[dir] {
+			if matched, pattern := file.matcher.MatchesPathHow(rel); matched {
+				ignored = true
+				rule = fmt.Sprintf("%s:%d:%s", file.source, pattern.LineNo, pattern.Line)
+			} else if ignored && file.negations.MatchesPath(rel) {
+				ignored = false
+				rule = ""
+			}
+		}
+	}
+
+	return ignored, rule
+}
+
+// ancestors returns the directories from the scan root to the parent of path
+func (m *ignoreMatcher) ancestors(path string) []string {
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == m.root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	// reverse to root first order
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
+
+func withDirSuffix(path string, isDir bool) string {
+	if isDir {
+		return path + "/"
+	}
+	return path
+}
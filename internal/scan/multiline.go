@@ -0,0 +1,180 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"sort"
+	"strings"
+)
+
+// MultilineMaxLines is the most lines a multiline match may span. Patterns
+// are evaluated against windows of twice as many lines, advancing by half a
+// window, so every match of up to this length is seen whole exactly once.
+const MultilineMaxLines = 128
+
+// multilineWindow buffers the lines of one file or diff for the multiline
+// patterns of a scanner
+type multilineWindow struct {
+	scanner  *Scanner
+	src      source
+	patterns []models.CompiledPattern
+	lines    []extractors.Line
+	previous string // the line before the window
+}
+
+// newMultilineWindow returns nil when no multiline patterns are loaded, the
+// methods of a nil window do nothing
+func (s *Scanner) newMultilineWindow(src source) *multilineWindow {
+	var patterns []models.CompiledPattern
+	for _, pattern := range s.patterns {
+		if pattern.Multiline {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	return &multilineWindow{scanner: s, src: src, patterns: patterns}
+}
+
+// add appends a line to the window, returning the findings of any matches
+// which can no longer grow
+func (w *multilineWindow) add(line extractors.Line) []models.Finding {
+	if w == nil {
+		return nil
+	}
+
+	// later windows of a long line repeat no line breaks, only whole
+	// lines up to the window size take part
+	if line.Offset > 0 {
+		return nil
+	}
+
+	// a gap, such as between diff hunks, starts a new window
+	if n := len(w.lines); n > 0 && line.Number != w.lines[n-1].Number+1 {
+		findings := w.flush()
+		w.lines = append(w.lines, line)
+		return findings
+	}
+
+	w.lines = append(w.lines, line)
+	if len(w.lines) < 2*MultilineMaxLines {
+		return nil
+	}
+
+	findings := w.match(MultilineMaxLines)
+	w.previous = w.lines[MultilineMaxLines-1].Text
+	w.lines = append(w.lines[:0], w.lines[MultilineMaxLines:]...)
+	return findings
+}
+
+// flush returns the findings of all remaining matches and empties the window
+func (w *multilineWindow) flush() []models.Finding {
+	if w == nil || len(w.lines) == 0 {
+		return nil
+	}
+
+	findings := w.match(len(w.lines))
+	w.lines = w.lines[:0]
+	w.previous = ""
+	return findings
+}
+
+// match evaluates the multiline patterns against the window, reporting
+// matches starting within its first limit lines
+func (w *multilineWindow) match(limit int) []models.Finding {
+	var findings []models.Finding
+
+	// byte offset of each line within the joined text
+	starts := make([]int, len(w.lines))
+	var text strings.Builder
+	for index, line := range w.lines {
+		if index > 0 {
+			text.WriteByte('\n')
+		}
+		starts[index] = text.Len()
+		text.WriteString(line.Text)
+	}
+	content := text.String()
+
+	lineOf := func(offset int) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+	}
+
+	for _, pattern := range w.patterns {
+		for _, location := range pattern.Regex.FindAllStringSubmatchIndex(content, -1) {
+			secretStart, secretEnd := secretSpan(pattern, location)
+
+			first := lineOf(secretStart)
+			if first >= limit {
+				continue // seen whole by the next window
+			}
+			// the last byte of the secret, or its start when empty
+			last := lineOf(max(secretEnd-1, secretStart))
+
+			match := content[secretStart:secretEnd]
+			startLine, endLine := w.lines[first], w.lines[last]
+			around := surroundings{before: w.lines[:first], after: w.lines[last+1:]}
+			accepted := w.scanner.validate(pattern, match, func() validators.Context {
+				text := content[starts[first]:lineEnd(content, starts, last)]
+				return around.context(w.src, text, startLine.Number, endLine.Number, pattern.ContextLines)
+			})
+			if !accepted && !w.scanner.keepRejected {
+				continue
+			}
+
+			start := secretStart - starts[first]
+			finding := models.Finding{
+				File:      w.src.path,
+				Line:      startLine.Number,
+				EndLine:   endLine.Number,
+				Column:    start + 1,
+				EndColumn: secretEnd - starts[last] + 1,
+				Pattern:   pattern.Name,
+				Severity:  pattern.Severity,
+				Match:     match,
+
+				Fingerprint: Fingerprint(w.src.relPath, pattern.Name, match),
+				Rejected:    !accepted,
+			}
+			if full := content[location[0]:location[1]]; full != match {
+				finding.Context = full
+			}
+			w.scanner.locate(&finding, w.src, startLine, start)
+
+			previous := w.previous
+			if first > 0 {
+				previous = w.lines[first-1].Text
+			}
+			if w.scanner.admit(&finding, startLine.Text, previous) {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings
+}
+
+// lineEnd returns the offset of the end of line index in the joined text
+func lineEnd(content string, starts []int, index int) int {
+	if index+1 < len(starts) {
+		return starts[index+1] - 1
+	}
+	return len(content)
+}
@@ -15,49 +15,145 @@
 package scan
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
+	"github.com/clarityoverclever/secret-scan/internal/baseline"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/output"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"secret-scan/internal/extractors"
-	"secret-scan/internal/models"
-	"secret-scan/internal/validators"
+	"regexp"
 	"sync"
+	"sync/atomic"
 )
 
 type Scanner struct {
-	registry   *extractors.Registry
-	patterns   []models.CompiledPattern
-	validators *validators.Registry
-	encoder    *json.Encoder
-	logger     *slog.Logger
-	numWorkers int
-	mutex      sync.Mutex
+	registry       *extractors.Registry
+	patterns       []models.CompiledPattern
+	writer         output.Writer
+	logger         *slog.Logger
+	numWorkers     int
+	contextLines   int // the most lines of context any pattern needs
+	excludes       []string
+	includes       []string
+	showSuppressed bool
+	keepRejected   bool
+	baseline       *baseline.Baseline
+	allowlist      []*regexp.Regexp
+	archiveLimits  extractors.ArchiveLimits
+	redact         redact.Mode
+	mutex          sync.Mutex
+
+	findingCount    atomic.Int64
+	suppressedCount atomic.Int64
+	baselineCount   atomic.Int64
+	allowlistCount  atomic.Int64
+	maxSeverity     models.Severity // guarded by mutex
+	writeErr        error           // guarded by mutex
+}
+
+type Options struct {
+	Workers  int
+	Excludes []string // gitignore style globs of paths to skip
+	Includes []string // gitignore style globs, when set only matching files are scanned
+
+	// ShowSuppressed emits findings waived by inline comments, flagged as suppressed
+	ShowSuppressed bool
+
+	// KeepRejected emits matches failing the validators of their pattern,
+	// flagged as rejected, so patterns can be tested
+	KeepRejected bool
+
+	// Baseline drops findings whose fingerprint was accepted in a previous run
+	Baseline *baseline.Baseline
+
+	// Allowlist drops findings whose secret matches one of the expressions,
+	// such as documented example values
+	Allowlist []*regexp.Regexp
+
+	// Extractors are consulted before the built-in extractors and archive
+	// formats, e.g. plugins
+	Extractors []extractors.Extractor
+
+	// ArchiveLimits bounds the scanning of archives, MaxDepth 0 disables it
+	ArchiveLimits extractors.ArchiveLimits
+
+	// Redact controls how secrets are written to findings and debug logs
+	Redact redact.Mode
+}
+
+// Stats summarises a completed scan
+type Stats struct {
+	Findings   int64
+	Suppressed int64
+	Baselined  int64
+	Allowed    int64 // dropped by the allowlist
+
+	// MaxSeverity is the highest severity among reported, unsuppressed findings
+	MaxSeverity models.Severity
 }
 
 type scanJob struct {
 	path      string
+	relPath   string // path relative to the scan root, used for fingerprints
 	extractor extractors.Extractor
+	container extractors.Container // set for archives instead of extractor
+	diff      *diffFile            // set for git history and staged changes instead of extractor
 }
 
-type Finding struct {
-	File     string `json:"file"`
-	Line     int    `json:"line"`
-	Pattern  string `json:"pattern"`
-	Severity string `json:"severity"`
-	Match    string `json:"match"`
+// source identifies the content of a file, archive member or diff
+type source struct {
+	path     string // reported path, archive members are appended after !
+	relPath  string // path relative to the scan root, used for fingerprints
+	location models.Location
 }
 
-func NewScanner(patterns []models.CompiledPattern, encoder *json.Encoder, log *slog.Logger, numWorkers int) *Scanner {
+func NewScanner(patterns []models.CompiledPattern, writer output.Writer, log *slog.Logger, opts Options) *Scanner {
+	extractorRegistry := extractors.NewRegistry()
+	for _, extractor := range opts.Extractors {
+		extractorRegistry.Register(extractor)
+	}
+
+	contextLines := 0
+	for _, pattern := range patterns {
+		contextLines = max(contextLines, pattern.ContextLines)
+	}
+
 	return &Scanner{
-		registry:   extractors.NewRegistry(),
-		patterns:   patterns,
-		validators: validators.NewRegistry(),
-		encoder:    encoder,
-		logger:     log,
-		numWorkers: numWorkers,
+		registry:       extractorRegistry,
+		contextLines:   contextLines,
+		patterns:       patterns,
+		writer:         writer,
+		logger:         log,
+		numWorkers:     opts.Workers,
+		excludes:       opts.Excludes,
+		includes:       opts.Includes,
+		showSuppressed: opts.ShowSuppressed,
+		keepRejected:   opts.KeepRejected,
+		baseline:       opts.Baseline,
+		allowlist:      opts.Allowlist,
+		archiveLimits:  opts.ArchiveLimits,
+		redact:         opts.Redact,
+	}
+}
+
+func (s *Scanner) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return Stats{
+		Findings:   s.findingCount.Load(),
+		Suppressed: s.suppressedCount.Load(),
+		Baselined:  s.baselineCount.Load(),
+		Allowed:    s.allowlistCount.Load(),
+
+		MaxSeverity: s.maxSeverity,
 	}
 }
 
@@ -71,24 +167,58 @@ func (s *Scanner) ScanPath(ctx context.Context, root string) error {
 		go s.worker(ctx, i, jobs, &wg)
 	}
 
+	ignored := newIgnoreMatcher(root, s.excludes, s.includes)
+
 	walkError := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
+			// an unreadable root means nothing was scanned
+			if path == root {
+				return err
+			}
 			s.logger.Error("error accessing path", "path", path, "error", err)
 			return nil
 		}
 
+		if skip, rule := ignored.match(path, d.IsDir()); skip {
+			s.logger.Debug("skipping ignored path", "path", path, "rule", rule)
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
 		if d.IsDir() {
+			if err := ignored.load(path); err != nil {
+				s.logger.Warn("failed to load ignore files", "path", path, "error", err)
+			}
 			return nil
 		}
 
 		// check if an extractor is available for this file
-		extractor := s.registry.Get(path)
-		if extractor == nil {
+		sample, err := extractors.ReadSample(path)
+		if err != nil {
+			s.logger.Warn("failed to classify file", "path", path, "error", err)
 			return nil
 		}
 
+		var container extractors.Container
+		if s.archiveLimits.MaxDepth > 0 {
+			container = s.registry.MatchContainer(path, sample)
+		}
+		extractor := s.registry.Match(path, sample)
+
+		if extractor == nil && container == nil {
+			s.logger.Debug("skipping unsupported file", "path", path)
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil || relPath == "." {
+			relPath = filepath.Base(path)
+		}
+
 		select {
-		case jobs <- scanJob{path: path, extractor: extractor}:
+		case jobs <- scanJob{path: path, relPath: relPath, extractor: extractor, container: container}:
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -99,7 +229,51 @@ func (s *Scanner) ScanPath(ctx context.Context, root string) error {
 	close(jobs)
 	wg.Wait()
 
-	return walkError
+	if walkError != nil {
+		return walkError
+	}
+
+	// findings that could not be written make the scan incomplete
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.writeErr
+}
+
+// ScanReader scans content which does not come from the file system, such as
+// an upload. name is reported as the path of the findings and, with the
+// leading bytes of the content, selects the extractor.
+func (s *Scanner) ScanReader(ctx context.Context, name string, r io.Reader) error {
+	buffered := bufio.NewReaderSize(r, extractors.SampleSize)
+	sample, err := buffered.Peek(extractors.SampleSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+
+	src := source{path: name, relPath: name}
+
+	var container extractors.Container
+	if s.archiveLimits.MaxDepth > 0 {
+		container = s.registry.MatchContainer(name, sample)
+	}
+
+	switch extractor := s.registry.Match(name, sample); {
+	case container != nil:
+		budget := extractors.NewBudget(s.archiveLimits)
+		err = s.scanContainer(ctx, container, buffered, src, budget, 1)
+	case extractor != nil:
+		err = s.scanContent(ctx, extractor, buffered, src)
+	default:
+		s.logger.Debug("skipping unsupported content", "path", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// findings that could not be written make the scan incomplete
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.writeErr
 }
 
 func (s *Scanner) worker(ctx context.Context, id int, jobs <-chan scanJob, wg *sync.WaitGroup) {
@@ -112,7 +286,13 @@ func (s *Scanner) worker(ctx context.Context, id int, jobs <-chan scanJob, wg *s
 			s.logger.Debug("worker stopped", "id", id)
 			return
 		default:
-			if err := s.scanFile(ctx, job.path, job.extractor); err != nil {
+			var err error
+			if job.diff != nil {
+				err = s.scanDiff(job)
+			} else {
+				err = s.scanFile(ctx, job)
+			}
+			if err != nil {
 				s.logger.Error("failed to scan file", "path", job.path, "error", err)
 			}
 		}
@@ -121,7 +301,8 @@ func (s *Scanner) worker(ctx context.Context, id int, jobs <-chan scanJob, wg *s
 	s.logger.Debug("worker finished", "id", id)
 }
 
-func (s *Scanner) scanFile(ctx context.Context, path string, extractor extractors.Extractor) error {
+func (s *Scanner) scanFile(ctx context.Context, job scanJob) error {
+	path := job.path
 	s.logger.Debug("scanning file", "path", path)
 
 	f, err := os.Open(path)
@@ -131,50 +312,242 @@ func (s *Scanner) scanFile(ctx context.Context, path string, extractor extractor
 	}
 	defer f.Close()
 
-	lines, err := extractor.Extract(ctx, f)
-	if err != nil {
-		s.logger.Warn("failed to extract lines", "path", path, "error", err)
+	src := source{path: path, relPath: job.relPath}
+	if job.container != nil {
+		budget := extractors.NewBudget(s.archiveLimits)
+		return s.scanContainer(ctx, job.container, f, src, budget, 1)
+	}
+
+	return s.scanContent(ctx, job.extractor, f, src)
+}
+
+// scanContent scans the lines of a file or archive member as they are
+// extracted, so memory use does not grow with the size of the content
+func (s *Scanner) scanContent(ctx context.Context, extractor extractors.Extractor, r io.Reader, src source) error {
+	findings := make([]models.Finding, 0)
+	multiline := s.newMultilineWindow(src)
+	queue := lineQueue{context: s.contextLines}
+
+	var current extractors.Line
+	previous, last := "", ""
+	scanned := false
+
+	scan := func(index int) {
+		line := queue.lines[index]
+
+		// windows of a long line share the preceding line
+		if !scanned || line.Number != current.Number {
+			previous = ""
+			if scanned && current.Number == line.Number-1 {
+				previous = last
+			}
+		}
+		current, last, scanned = line, line.Text, true
+
+		findings = append(findings, s.scanLine(src, line, previous, queue.surrounding(index))...)
+	}
+
+	extracted := 0
+	err := extractor.Extract(ctx, r, func(line extractors.Line) error {
+		extracted = line.Number
+		queue.push(line, scan)
+		findings = append(findings, multiline.add(line)...)
 		return nil
+	})
+	queue.flush(scan)
+	findings = append(findings, multiline.flush()...)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if extracted == 0 {
+			s.logger.Warn("failed to extract lines", "path", src.path, "error", err)
+			return nil
+		}
+		// still report what was read before the failure
+		s.logger.Warn("content truncated, scanning lines read before the error",
+			"path", src.path,
+			"lines", extracted,
+			"error", err,
+		)
 	}
 
-	findings := make([]Finding, 0)
-	for lineNum, line := range lines {
-		for _, pattern := range s.patterns {
-			if pattern.Regex.MatchString(line) {
-				matches := pattern.Regex.FindAllString(line, -1)
-				// run the match against a validator if specified
-				for _, match := range matches {
-					if pattern.Validator != "" {
-						validator := s.validators.Get(pattern.Validator)
-						if validator != nil && !validator.Validate(match, line) {
-							s.logger.Debug("match failed validation",
-								"pattern", pattern.Name,
-								"validator", pattern.Validator,
-							)
-							continue // skip this match
-						}
-					}
-					findings = append(findings, Finding{
-						File:     path,
-						Line:     lineNum + 1,
-						Pattern:  pattern.Name,
-						Severity: pattern.Severity,
-						Match:    match,
-					})
-				}
+	return s.emit(findings)
+}
+
+// scanLine matches a single line, or window of a long line, against every
+// single line pattern. previous is the preceding line of the file, which may
+// carry an inline suppression.
+func (s *Scanner) scanLine(src source, window extractors.Line, previous string, around surroundings) []models.Finding {
+	var findings []models.Finding
+	line := window.Text
+
+	for _, pattern := range s.patterns {
+		if pattern.Multiline || !pattern.Regex.MatchString(line) {
+			continue
+		}
+
+		locations := pattern.Regex.FindAllStringSubmatchIndex(line, -1)
+		for _, location := range locations {
+			// reported by the previous window of this line
+			if location[1] <= window.Overlap {
+				continue
+			}
+
+			secretStart, secretEnd := secretSpan(pattern, location)
+			match := line[secretStart:secretEnd]
+			start := window.Offset + secretStart // within the full line
+
+			// run the match against a validator if specified
+			accepted := s.validate(pattern, match, func() validators.Context {
+				return around.context(src, line, window.Number, window.Number, pattern.ContextLines)
+			})
+			if !accepted && !s.keepRejected {
+				continue // skip this match
+			}
+
+			finding := models.Finding{
+				File:      src.path,
+				Line:      window.Number,
+				EndLine:   window.Number,
+				Column:    start + 1,
+				EndColumn: start + len(match) + 1,
+				Pattern:   pattern.Name,
+				Severity:  pattern.Severity,
+				Match:     match,
+
+				Fingerprint: Fingerprint(src.relPath, pattern.Name, match),
+				Rejected:    !accepted,
+			}
+			if full := line[location[0]:location[1]]; full != match {
+				finding.Context = full
+			}
+			s.locate(&finding, src, window, start)
+
+			if s.admit(&finding, line, previous) {
+				findings = append(findings, finding)
 			}
 		}
 	}
 
-	if len(findings) > 0 {
-		s.mutex.Lock()
-		defer s.mutex.Unlock()
+	return findings
+}
 
-		for _, finding := range findings {
-			if err := s.encoder.Encode(finding); err != nil {
-				s.logger.Error("failed to encode finding", "error", err)
-				return err
+// secretSpan returns the bounds of the secret within a match location, the
+// capture group if it took part in the match
+func secretSpan(pattern models.CompiledPattern, location []int) (int, int) {
+	if group := pattern.SecretGroup; group > 0 && location[2*group] >= 0 {
+		return location[2*group], location[2*group+1]
+	}
+	return location[0], location[1]
+}
+
+// validate runs the validators of a pattern, if it has any, against a match.
+// context is only built when needed.
+func (s *Scanner) validate(pattern models.CompiledPattern, match string, context func() validators.Context) bool {
+	if pattern.Validator == nil {
+		return true
+	}
+
+	if !pattern.Validator.Validate(match, context()) {
+		s.logger.Debug("match failed validation", "pattern", pattern.Name, "match", s.redact.Secret(match))
+		return false
+	}
+	return true
+}
+
+// admit applies inline suppressions, the allowlist and the baseline to a
+// finding and counts it, returning whether it is reported. line is the line
+// the finding starts on and previous the line before it.
+func (s *Scanner) admit(finding *models.Finding, line string, previous string) bool {
+	// honor inline suppression on this line or a comment line preceding it
+	if suppressed(line, finding.Pattern) || suppressesNext(previous, finding.Pattern) {
+		s.suppressedCount.Add(1)
+		s.logger.Debug("match suppressed inline",
+			"path", finding.File, "line", finding.Line, "pattern", finding.Pattern, "match", s.redact.Secret(finding.Match))
+		if !s.showSuppressed {
+			return false
+		}
+		finding.Suppressed = true
+		return true
+	}
+
+	for _, allowed := range s.allowlist {
+		if allowed.MatchString(finding.Match) {
+			s.allowlistCount.Add(1)
+			s.logger.Debug("match allowlisted",
+				"path", finding.File, "line", finding.Line, "pattern", finding.Pattern, "allowlist", allowed.String())
+			return false
+		}
+	}
+
+	if s.baseline.Contains(finding.Fingerprint) {
+		s.baselineCount.Add(1)
+		s.logger.Debug("match present in baseline",
+			"path", finding.File, "line", finding.Line, "pattern", finding.Pattern, "match", s.redact.Secret(finding.Match))
+		return false
+	}
+
+	// rejected matches are kept for testing patterns and are not findings
+	if !finding.Rejected {
+		s.findingCount.Add(1)
+	}
+	return true
+}
+
+// locate sets the byte offset and extractor specific location of a finding
+// starting at byte offset start of the full line
+func (s *Scanner) locate(finding *models.Finding, src source, window extractors.Line, start int) {
+	location := src.location
+	if window.Location.Segment != "" {
+		location.Segment = window.Location.Segment
+	}
+
+	if region := window.Region(start); region != nil {
+		// offsets within a region, such as a cell, are relative to it
+		location.Sheet = region.Location.Sheet
+		location.Cell = region.Location.Cell
+		offset := int64(start - region.Start)
+		finding.Offset = &offset
+	} else if window.Start >= 0 {
+		offset := window.Start + int64(start)
+		finding.Offset = &offset
+	}
+
+	if !location.IsZero() {
+		finding.Location = &location
+	}
+}
+
+// emit writes the findings of one file or diff as a contiguous block,
+// hashing and redacting their secrets
+func (s *Scanner) emit(findings []models.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	for index := range findings {
+		finding := &findings[index]
+		finding.MatchSHA256 = redact.Hash(finding.Match)
+		finding.Context = s.redact.Text(finding.Context, finding.Match)
+		finding.Match = s.redact.Secret(finding.Match)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, finding := range findings {
+		if err := s.writer.Write(finding); err != nil {
+			s.logger.Error("failed to write finding", "error", err)
+			if s.writeErr == nil {
+				s.writeErr = err
 			}
+			return err
+		}
+
+		if !finding.Suppressed && !finding.Rejected && finding.Severity > s.maxSeverity {
+			s.maxSeverity = finding.Severity
 		}
 	}
 	return nil
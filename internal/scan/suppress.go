@@ -0,0 +1,75 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import "strings"
+
+// SuppressionMarker waives findings on the same line, or on the following
+// line when the marker is the only content of its line.
+// "secret-scan:ignore" waives every pattern, "secret-scan:ignore=Name,Other"
+// waives only the named patterns.
+const SuppressionMarker = "secret-scan:ignore"
+
+// commentTerminators are stripped from the end of a pattern list
+var commentTerminators = []string{"*/", "-->", "%>", "#}", "}}"}
+
+// commentOpeners are the characters which may precede a marker on a line of
+// its own, such as # or // or <!--
+const commentOpeners = "#/*-;%!<{'"
+
+// suppressesNext reports whether line is a comment of its own carrying a
+// marker waiving the named pattern on the following line. A marker trailing
+// code only waives findings on its own line.
+func suppressesNext(line string, pattern string) bool {
+	index := strings.Index(line, SuppressionMarker)
+	if index < 0 {
+		return false
+	}
+	if strings.Trim(line[:index], commentOpeners+" \t") != "" {
+		return false
+	}
+	return suppressed(line, pattern)
+}
+
+// suppressed reports whether line carries a marker waiving the named pattern
+func suppressed(line string, pattern string) bool {
+	index := strings.Index(line, SuppressionMarker)
+	if index < 0 {
+		return false
+	}
+
+	rest := line[index+len(SuppressionMarker):]
+	if !strings.HasPrefix(rest, "=") {
+		// a bare marker waives all patterns, but not a different word
+		// such as "secret-scan:ignored"
+		return rest == "" || !isWordChar(rest[0])
+	}
+
+	names := strings.TrimSpace(rest[1:])
+	for _, terminator := range commentTerminators {
+		names = strings.TrimSpace(strings.TrimSuffix(names, terminator))
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
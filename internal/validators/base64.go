@@ -16,8 +16,10 @@ package validators
 
 import "encoding/base64"
 
+// Base64HighEntropyValidator accepts a match which decodes as base64 to data
+// with an entropy above entropyThreshold
 func Base64HighEntropyValidator(entropyThreshold float64) ValidatorFunc {
-	return func(match string, context string) bool {
+	return func(match string, context Context) bool {
 		// Check if valid Base64
 		decoded, err := base64.StdEncoding.DecodeString(match)
 		if err != nil {
@@ -27,3 +29,17 @@ func Base64HighEntropyValidator(entropyThreshold float64) ValidatorFunc {
 		return calculateEntropy(string(decoded)) > entropyThreshold
 	}
 }
+
+// base64Factory builds a Base64HighEntropyValidator from
+// {"base64_entropy", min = 4.5}
+func base64Factory(params Params) (Validator, error) {
+	if err := params.only("min"); err != nil {
+		return nil, err
+	}
+
+	threshold, err := params.Float("min", 4.5)
+	if err != nil {
+		return nil, err
+	}
+	return Base64HighEntropyValidator(threshold), nil
+}
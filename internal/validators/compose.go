@@ -0,0 +1,47 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+// All accepts a match when every validator accepts it, stopping at the first
+// which rejects it
+func All(validators ...Validator) Validator {
+	if len(validators) == 1 {
+		return validators[0]
+	}
+	return ValidatorFunc(func(match string, context Context) bool {
+		for _, validator := range validators {
+			if !validator.Validate(match, context) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Any accepts a match when at least one validator accepts it, stopping at the
+// first which does
+func Any(validators ...Validator) Validator {
+	if len(validators) == 1 {
+		return validators[0]
+	}
+	return ValidatorFunc(func(match string, context Context) bool {
+		for _, validator := range validators {
+			if validator.Validate(match, context) {
+				return true
+			}
+		}
+		return false
+	})
+}
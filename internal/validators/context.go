@@ -0,0 +1,56 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextValidator accepts a match when its line or the surrounding lines
+// declared by the pattern contain one of the keywords, ignoring case
+func ContextValidator(keywords []string) ValidatorFunc {
+	lowered := make([]string, len(keywords))
+	for index, keyword := range keywords {
+		lowered[index] = strings.ToLower(keyword)
+	}
+
+	return func(match string, context Context) bool {
+		lower := strings.ToLower(strings.Join(context.Lines(), "\n"))
+		for _, keyword := range lowered {
+			if strings.Contains(lower, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// contextFactory builds a ContextValidator from {"context", keywords = {...}}
+func contextFactory(params Params) (Validator, error) {
+	if err := params.only("keywords"); err != nil {
+		return nil, err
+	}
+
+	keywords, err := params.Strings("keywords")
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("parameter keywords is required")
+	}
+
+	return ContextValidator(keywords), nil
+}
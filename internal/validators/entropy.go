@@ -16,12 +16,27 @@ package validators
 
 import "math"
 
+// EntropyValidator accepts a match whose shannon entropy in bits per
+// character is at least entropyThreshold
 func EntropyValidator(entropyThreshold float64) ValidatorFunc {
-	return func(match string, context string) bool {
+	return func(match string, context Context) bool {
 		return calculateEntropy(match) >= entropyThreshold
 	}
 }
 
+// entropyFactory builds an EntropyValidator from {"entropy", min = 4.2}
+func entropyFactory(params Params) (Validator, error) {
+	if err := params.only("min"); err != nil {
+		return nil, err
+	}
+
+	threshold, err := params.Float("min", 3.5)
+	if err != nil {
+		return nil, err
+	}
+	return EntropyValidator(threshold), nil
+}
+
 func calculateEntropy(s string) float64 {
 	if len(s) == 0 {
 		return 0
@@ -0,0 +1,82 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validators
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Params configures a validator built by a Factory, as given in a pattern
+// file, e.g. {"entropy", min = 4.2}
+type Params map[string]any
+
+// Factory builds a validator from parameters
+type Factory func(params Params) (Validator, error)
+
+// Float returns a numeric parameter, or fallback when it is absent
+func (p Params) Float(key string, fallback float64) (float64, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return fallback, nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("parameter %s must be a number", key)
+	}
+}
+
+// Strings returns a parameter holding a string or a list of strings
+func (p Params) Strings(key string) ([]string, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("parameter %s must be a list of strings", key)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("parameter %s must be a string or a list of strings", key)
+	}
+}
+
+// only rejects parameters other than keys, so a misspelt threshold does not
+// silently fall back to its default
+func (p Params) only(keys ...string) error {
+	var unknown []string
+	for key := range p {
+		if !slices.Contains(keys, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown parameters %v", unknown)
+	}
+	return nil
+}
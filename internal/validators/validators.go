@@ -14,22 +14,53 @@
 
 package validators
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownValidator is returned when resolving a name which is neither a
+// registered validator nor a factory
+var ErrUnknownValidator = errors.New("unknown validator")
+
 type Validator interface {
-	Validate(match string, context string) bool
+	Validate(match string, context Context) bool
 }
 
-type ValidatorFunc func(match string, context string) bool
+type ValidatorFunc func(match string, context Context) bool
 
-func (f ValidatorFunc) Validate(match string, context string) bool {
+func (f ValidatorFunc) Validate(match string, context Context) bool {
 	return f(match, context)
 }
 
+// Context describes where a match was found. Before and After hold up to
+// the number of lines of context declared by the pattern.
+type Context struct {
+	Line     string   // the line containing the match, all lines of a multiline match
+	Before   []string // preceding lines, nearest last
+	After    []string // following lines, nearest first
+	Path     string   // path of the file, archive members are appended after !
+	FileType string   // lower case extension of the file without the dot, e.g. "yaml"
+}
+
+// Lines returns the preceding lines, the line and the following lines in order
+func (c Context) Lines() []string {
+	lines := make([]string, 0, len(c.Before)+1+len(c.After))
+	lines = append(lines, c.Before...)
+	lines = append(lines, c.Line)
+	return append(lines, c.After...)
+}
+
 type Registry struct {
 	validators map[string]Validator
+	factories  map[string]Factory
 }
 
 func NewRegistry() *Registry {
-	r := &Registry{validators: make(map[string]Validator)}
+	r := &Registry{
+		validators: make(map[string]Validator),
+		factories:  make(map[string]Factory),
+	}
 
 	r.RegisterDefaults()
 	return r
@@ -41,12 +72,53 @@ func (r *Registry) RegisterDefaults() {
 	r.Register("entropy_high", EntropyValidator(5.5))
 	r.Register("base64_high_entropy", Base64HighEntropyValidator(4.5))
 	r.Register("azure_context", AzureContextValidator)
+
+	r.RegisterFactory("entropy", entropyFactory)
+	r.RegisterFactory("base64_entropy", base64Factory)
+	r.RegisterFactory("context", contextFactory)
 }
 
+// Register adds a validator, replacing a validator or factory of that name
 func (r *Registry) Register(name string, validator Validator) {
+	delete(r.factories, name)
 	r.validators[name] = validator
 }
 
 func (r *Registry) Get(name string) Validator {
 	return r.validators[name]
 }
+
+// RegisterFactory adds a validator which is built from the parameters given
+// by each pattern referencing it, replacing a validator or factory of that name
+func (r *Registry) RegisterFactory(name string, factory Factory) {
+	delete(r.validators, name)
+	r.factories[name] = factory
+}
+
+// Has reports whether a validator or factory is registered under name
+func (r *Registry) Has(name string) bool {
+	_, validator := r.validators[name]
+	_, factory := r.factories[name]
+	return validator || factory
+}
+
+// Resolve returns the validator registered under name, or builds one with
+// the factory of that name. Registered validators take no parameters.
+func (r *Registry) Resolve(name string, params Params) (Validator, error) {
+	if factory, ok := r.factories[name]; ok {
+		validator, err := factory(params)
+		if err != nil {
+			return nil, fmt.Errorf("validator %s: %w", name, err)
+		}
+		return validator, nil
+	}
+
+	validator, ok := r.validators[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownValidator, name)
+	}
+	if len(params) > 0 {
+		return nil, fmt.Errorf("validator %s takes no parameters", name)
+	}
+	return validator, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretscan
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/plugins"
+	"github.com/clarityoverclever/secret-scan/internal/scan"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"log/slog"
+	"runtime"
+)
+
+// Option configures a Scanner, see New
+type Option func(*settings)
+
+type settings struct {
+	logger            *slog.Logger
+	patternsPath      string
+	noDefaultPatterns bool
+	filter            plugins.PatternFilter
+	lenient           bool
+	validators        []namedValidator
+	pluginsPath       string
+	allowlist         []string
+	baselinePath      string
+	redact            RedactMode
+	archiveLimits     ArchiveLimits
+	scan              scan.Options
+}
+
+type namedValidator struct {
+	name      string
+	validator validators.Validator
+}
+
+func defaultSettings() settings {
+	return settings{
+		logger:        slog.New(slog.DiscardHandler),
+		redact:        RedactNone,
+		archiveLimits: DefaultArchiveLimits,
+		scan: scan.Options{
+			Workers: max(runtime.NumCPU()-1, 1),
+		},
+	}
+}
+
+// WithLogger receives the diagnostics of the scanner, which are discarded by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+// WithPatternsDir loads the .lua pattern files of a directory in addition to
+// the embedded patterns
+func WithPatternsDir(path string) Option {
+	return func(s *settings) {
+		s.patternsPath = path
+	}
+}
+
+// WithoutDefaultPatterns does not load the embedded patterns, only those of
+// WithPatternsDir
+func WithoutDefaultPatterns() Option {
+	return func(s *settings) {
+		s.noDefaultPatterns = true
+	}
+}
+
+// WithMinSeverity only compiles patterns at or above a severity
+func WithMinSeverity(severity Severity) Option {
+	return func(s *settings) {
+		s.filter.MinSeverity = models.Severity(severity)
+	}
+}
+
+// WithSeverities only compiles patterns with one of the severities
+func WithSeverities(severities ...Severity) Option {
+	return func(s *settings) {
+		for _, severity := range severities {
+			s.filter.Severities = append(s.filter.Severities, models.Severity(severity))
+		}
+	}
+}
+
+// WithOnlyPatterns only compiles the named patterns
+func WithOnlyPatterns(names ...string) Option {
+	return func(s *settings) {
+		s.filter.Only = append(s.filter.Only, names...)
+	}
+}
+
+// WithSkipPatterns does not compile the named patterns
+func WithSkipPatterns(names ...string) Option {
+	return func(s *settings) {
+		s.filter.Skip = append(s.filter.Skip, names...)
+	}
+}
+
+// WithLenientValidators disables patterns whose validators cannot be
+// resolved, by default New fails
+func WithLenientValidators() Option {
+	return func(s *settings) {
+		s.lenient = true
+	}
+}
+
+// WithValidator registers a validator patterns may reference by name,
+// replacing a built-in or pattern file validator of that name. fn may be
+// called concurrently.
+func WithValidator(name string, fn func(match string, context ValidatorContext) bool) Option {
+	return func(s *settings) {
+		s.validators = append(s.validators, namedValidator{name: name, validator: validatorFunc(fn)})
+	}
+}
+
+// WithPluginsDir loads the Lua extractor plugins of a directory
+func WithPluginsDir(path string) Option {
+	return func(s *settings) {
+		s.pluginsPath = path
+	}
+}
+
+// WithWorkers sets the number of files ScanPath scans in parallel
+func WithWorkers(workers int) Option {
+	return func(s *settings) {
+		s.scan.Workers = max(workers, 1)
+	}
+}
+
+// WithExcludes skips paths matching the gitignore style globs in ScanPath
+func WithExcludes(globs ...string) Option {
+	return func(s *settings) {
+		s.scan.Excludes = append(s.scan.Excludes, globs...)
+	}
+}
+
+// WithIncludes only scans files matching the gitignore style globs in ScanPath
+func WithIncludes(globs ...string) Option {
+	return func(s *settings) {
+		s.scan.Includes = append(s.scan.Includes, globs...)
+	}
+}
+
+// WithAllowlist drops findings whose secret matches one of the regular
+// expressions, such as documented example values
+func WithAllowlist(expressions ...string) Option {
+	return func(s *settings) {
+		s.allowlist = append(s.allowlist, expressions...)
+	}
+}
+
+// WithBaseline drops findings whose fingerprint is present in a baseline file
+func WithBaseline(path string) Option {
+	return func(s *settings) {
+		s.baselinePath = path
+	}
+}
+
+// WithShowSuppressed reports findings waived by inline comments, flagged as suppressed
+func WithShowSuppressed() Option {
+	return func(s *settings) {
+		s.scan.ShowSuppressed = true
+	}
+}
+
+// WithRedact sets how secrets are written to findings and logs
+func WithRedact(mode RedactMode) Option {
+	return func(s *settings) {
+		s.redact = mode
+	}
+}
+
+// WithArchiveLimits bounds the scanning of archives, MaxDepth 0 disables it
+func WithArchiveLimits(limits ArchiveLimits) Option {
+	return func(s *settings) {
+		s.archiveLimits = limits
+	}
+}
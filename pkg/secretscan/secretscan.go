@@ -0,0 +1,243 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretscan embeds the secret-scan scanner in Go programs. A Scanner
+// loads and compiles its patterns once and may then scan directories, files
+// and in-memory content, such as uploads, concurrently:
+//
+//	scanner, err := secretscan.New(secretscan.WithRedact(secretscan.RedactPartial))
+//	if err != nil {
+//		return err
+//	}
+//	defer scanner.Close()
+//
+//	var findings []secretscan.Finding
+//	_, err = scanner.ScanReader(ctx, header.Filename, file, secretscan.Collect(&findings))
+package secretscan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/clarityoverclever/secret-scan/internal/baseline"
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/plugins"
+	"github.com/clarityoverclever/secret-scan/internal/redact"
+	"github.com/clarityoverclever/secret-scan/internal/scan"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+)
+
+// Handler receives the findings of a scan. Calls are never concurrent, an
+// error stops the scan and is returned by it.
+type Handler func(Finding) error
+
+// Collect returns a Handler appending findings to a slice
+func Collect(findings *[]Finding) Handler {
+	return func(finding Finding) error {
+		*findings = append(*findings, finding)
+		return nil
+	}
+}
+
+// Send returns a Handler sending findings to a channel, stopping the scan
+// when ctx is done before the finding is received. The channel is not closed.
+func Send(ctx context.Context, findings chan<- Finding) Handler {
+	return func(finding Finding) error {
+		select {
+		case findings <- finding:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Scanner scans for secrets with a fixed set of compiled patterns. It is
+// safe for concurrent use and must be closed once no longer used.
+type Scanner struct {
+	patterns []models.CompiledPattern
+	options  scan.Options
+	logger   *slog.Logger
+	closers  []func()
+}
+
+// New loads and compiles the patterns selected by the options. Without
+// options the embedded patterns are used with the defaults of the command
+// line tool.
+func New(opts ...Option) (*Scanner, error) {
+	settings := defaultSettings()
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	s := &Scanner{logger: settings.logger, options: settings.scan}
+
+	redactMode, err := redact.ParseMode(string(settings.redact))
+	if err != nil {
+		return nil, err
+	}
+	s.options.Redact = redactMode
+	s.options.ArchiveLimits = extractors.ArchiveLimits(settings.archiveLimits)
+
+	loader := plugins.NewPatternLoader(s.logger)
+	s.closers = append(s.closers, loader.Close)
+
+	definitions, err := loader.LoadPatterns(settings.patternsPath, settings.noDefaultPatterns)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	// validators defined by pattern files run on the loader VM, those of the
+	// caller replace any of the same name
+	registry := validators.NewRegistry()
+	loader.RegisterValidators(registry)
+	for _, named := range settings.validators {
+		registry.Register(named.name, named.validator)
+	}
+
+	s.patterns, err = loader.CompilePatterns(definitions, settings.filter, plugins.CompileOptions{
+		Validators: registry,
+		Lenient:    settings.lenient,
+		Redact:     s.options.Redact,
+	})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	if settings.pluginsPath != "" {
+		extractorLoader := plugins.NewExtractorLoader(s.logger)
+		s.closers = append(s.closers, extractorLoader.Close)
+
+		s.options.Extractors, err = extractorLoader.LoadExtractors(settings.pluginsPath)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to load extractor plugins: %w", err)
+		}
+	}
+
+	for _, expression := range settings.allowlist {
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("invalid allowlist expression: %w", err)
+		}
+		s.options.Allowlist = append(s.options.Allowlist, regex)
+	}
+
+	if settings.baselinePath != "" {
+		s.options.Baseline, err = baseline.Load(settings.baselinePath)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Close releases the Lua VMs running pattern validators and plugins
+func (s *Scanner) Close() {
+	for _, closer := range s.closers {
+		closer()
+	}
+	s.closers = nil
+}
+
+// Patterns returns the names of the compiled patterns
+func (s *Scanner) Patterns() []string {
+	names := make([]string, 0, len(s.patterns))
+	for _, pattern := range s.patterns {
+		names = append(names, pattern.Name)
+	}
+	return names
+}
+
+// ScanPath scans a file or the files of a directory, honoring ignore files
+// and the excludes and includes of the scanner
+func (s *Scanner) ScanPath(ctx context.Context, root string, handle Handler) (Stats, error) {
+	return s.run(ctx, handle, func(ctx context.Context, scanner *scan.Scanner) error {
+		return scanner.ScanPath(ctx, root)
+	})
+}
+
+// ScanReader scans content read from r. name is reported as the file of the
+// findings and, with the leading bytes of the content, selects how the
+// content is read, e.g. as text, a spreadsheet or an archive.
+func (s *Scanner) ScanReader(ctx context.Context, name string, r io.Reader, handle Handler) (Stats, error) {
+	return s.run(ctx, handle, func(ctx context.Context, scanner *scan.Scanner) error {
+		return scanner.ScanReader(ctx, name, r)
+	})
+}
+
+// ScanBytes scans content held in memory, see ScanReader
+func (s *Scanner) ScanBytes(ctx context.Context, name string, content []byte, handle Handler) (Stats, error) {
+	return s.ScanReader(ctx, name, bytes.NewReader(content), handle)
+}
+
+// run performs one scan with a scanner of its own, so concurrent scans
+// report separate findings and stats
+func (s *Scanner) run(ctx context.Context, handle Handler, fn func(context.Context, *scan.Scanner) error) (Stats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := &handlerWriter{handle: handle, cancel: cancel}
+	scanner := scan.NewScanner(s.patterns, writer, s.logger, s.options)
+
+	err := fn(ctx, scanner)
+	if handlerErr := writer.error(); handlerErr != nil {
+		// the scan was cancelled because of the handler
+		err = handlerErr
+	}
+	return newStats(scanner.Stats()), err
+}
+
+// handlerWriter passes findings to a Handler, cancelling the scan on error
+type handlerWriter struct {
+	handle Handler
+	cancel context.CancelFunc
+
+	mutex sync.Mutex
+	err   error
+}
+
+func (h *handlerWriter) Write(finding models.Finding) error {
+	if err := h.handle(newFinding(finding)); err != nil {
+		h.mutex.Lock()
+		if h.err == nil {
+			h.err = err
+		}
+		h.mutex.Unlock()
+
+		h.cancel()
+		return err
+	}
+	return nil
+}
+
+func (h *handlerWriter) Close() error {
+	return nil
+}
+
+func (h *handlerWriter) error() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.err
+}
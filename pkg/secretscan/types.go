@@ -0,0 +1,183 @@
+// Copyright 2026 Keith Marshall
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretscan
+
+import (
+	"github.com/clarityoverclever/secret-scan/internal/extractors"
+	"github.com/clarityoverclever/secret-scan/internal/models"
+	"github.com/clarityoverclever/secret-scan/internal/scan"
+	"github.com/clarityoverclever/secret-scan/internal/validators"
+)
+
+// Finding is a secret found by a scan, it encodes to the JSON written by the
+// command line tool
+type Finding struct {
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	EndLine   int       `json:"end_line"`         // differs from Line for multiline patterns
+	Column    int       `json:"column"`           // 1 based byte offset of the match within the line
+	EndColumn int       `json:"end_column"`       // column following the last byte of the match, on EndLine
+	Offset    *int64    `json:"offset,omitempty"` // byte offset of the match within the content, member or cell, nil when unknown such as for utf-16
+	Location  *Location `json:"location,omitempty"`
+	Pattern   string    `json:"pattern"`
+	Severity  Severity  `json:"severity"`
+	Match     string    `json:"match"`             // the secret, redacted as configured with WithRedact
+	Context   string    `json:"context,omitempty"` // the full match, when it differs from the secret
+
+	// MatchSHA256 is the hex sha256 of the secret before redaction
+	MatchSHA256 string `json:"match_sha256"`
+
+	// Fingerprint identifies the finding across scans, see WithBaseline
+	Fingerprint string `json:"fingerprint"`
+
+	// Suppressed is set for findings waived by inline comments, which are
+	// only reported with WithShowSuppressed
+	Suppressed bool `json:"suppressed,omitempty"`
+}
+
+// Location holds extractor specific coordinates of a finding
+type Location struct {
+	Archive string `json:"archive,omitempty"` // outermost archive containing the file
+	Member  string `json:"member,omitempty"`  // path within the archive, nested archives are joined with !
+	Sheet   string `json:"sheet,omitempty"`
+	Cell    string `json:"cell,omitempty"`    // cell reference including the sheet, e.g. Sheet2!C14
+	Segment string `json:"segment,omitempty"` // location reported by an extractor plugin, such as a key path
+}
+
+func newFinding(finding models.Finding) Finding {
+	converted := Finding{
+		File:        finding.File,
+		Line:        finding.Line,
+		EndLine:     finding.EndLine,
+		Column:      finding.Column,
+		EndColumn:   finding.EndColumn,
+		Offset:      finding.Offset,
+		Pattern:     finding.Pattern,
+		Severity:    Severity(finding.Severity),
+		Match:       finding.Match,
+		Context:     finding.Context,
+		MatchSHA256: finding.MatchSHA256,
+		Fingerprint: finding.Fingerprint,
+		Suppressed:  finding.Suppressed,
+	}
+
+	if location := finding.Location; location != nil {
+		converted.Location = &Location{
+			Archive: location.Archive,
+			Member:  location.Member,
+			Sheet:   location.Sheet,
+			Cell:    location.Cell,
+			Segment: location.Segment,
+		}
+	}
+	return converted
+}
+
+// Severity is ordered SeverityLow < SeverityMedium < SeverityHigh < SeverityCritical
+type Severity int
+
+const (
+	SeverityUnknown  = Severity(models.SeverityUnknown)
+	SeverityLow      = Severity(models.SeverityLow)
+	SeverityMedium   = Severity(models.SeverityMedium)
+	SeverityHigh     = Severity(models.SeverityHigh)
+	SeverityCritical = Severity(models.SeverityCritical)
+)
+
+// ParseSeverity converts a severity name such as "high", unknown names are an error
+func ParseSeverity(name string) (Severity, error) {
+	severity, err := models.ParseSeverity(name)
+	return Severity(severity), err
+}
+
+func (s Severity) String() string {
+	return models.Severity(s).String()
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return models.Severity(s).MarshalText()
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// RedactMode selects how much of a secret is kept in findings and logs
+type RedactMode string
+
+const (
+	RedactNone    RedactMode = "none"    // secrets are reported as found
+	RedactPartial RedactMode = "partial" // the ends of a secret are kept, e.g. AKIA************XYZ1
+	RedactFull    RedactMode = "full"    // secrets are replaced by [REDACTED]
+)
+
+// ArchiveLimits bounds the scanning of archives
+type ArchiveLimits struct {
+	MaxDepth     int     // nesting depth of archives, 0 disables archive scanning
+	MaxTotalSize int64   // bytes of the members scanned from one archive
+	MaxRatio     float64 // decompressed to compressed size ratio of a member
+}
+
+// DefaultArchiveLimits are the limits of the command line tool
+var DefaultArchiveLimits = ArchiveLimits(extractors.DefaultArchiveLimits)
+
+// ValidatorContext describes where a match was found for WithValidator. Before
+// and After hold up to the number of context lines declared by the pattern.
+type ValidatorContext struct {
+	Line     string   // the line containing the match, all lines of a multiline match
+	Before   []string // preceding lines, nearest last
+	After    []string // following lines, nearest first
+	Path     string   // path of the file, archive members are appended after !
+	FileType string   // lower case extension of the file without the dot, e.g. "yaml"
+}
+
+// validatorFunc adapts a WithValidator function to the scanner
+func validatorFunc(fn func(match string, context ValidatorContext) bool) validators.Validator {
+	return validators.ValidatorFunc(func(match string, context validators.Context) bool {
+		return fn(match, ValidatorContext{
+			Line:     context.Line,
+			Before:   context.Before,
+			After:    context.After,
+			Path:     context.Path,
+			FileType: context.FileType,
+		})
+	})
+}
+
+// Stats summarises a completed scan
+type Stats struct {
+	Findings   int64
+	Suppressed int64 // waived by inline comments
+	Baselined  int64 // present in the baseline
+	Allowed    int64 // dropped by the allowlist
+
+	// MaxSeverity is the highest severity among reported, unsuppressed findings
+	MaxSeverity Severity
+}
+
+func newStats(stats scan.Stats) Stats {
+	return Stats{
+		Findings:    stats.Findings,
+		Suppressed:  stats.Suppressed,
+		Baselined:   stats.Baselined,
+		Allowed:     stats.Allowed,
+		MaxSeverity: Severity(stats.MaxSeverity),
+	}
+}